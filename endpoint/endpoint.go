@@ -30,6 +30,8 @@ import (
 const (
 	// RecordTypeA is a RecordType enum value
 	RecordTypeA = "A"
+	// RecordTypeAAAA is a RecordType enum value
+	RecordTypeAAAA = "AAAA"
 	// RecordTypeCNAME is a RecordType enum value
 	RecordTypeCNAME = "CNAME"
 	// RecordTypeTXT is a RecordType enum value
@@ -40,6 +42,16 @@ const (
 	RecordTypeNS = "NS"
 	// RecordTypePTR is a RecordType enum value
 	RecordTypePTR = "PTR"
+	// RecordTypeCERT is a RecordType enum value
+	RecordTypeCERT = "CERT"
+	// RecordTypeDS is a RecordType enum value
+	RecordTypeDS = "DS"
+	// RecordTypeOPENPGPKEY is a RecordType enum value
+	RecordTypeOPENPGPKEY = "OPENPGPKEY"
+	// RecordTypeAPL is a RecordType enum value
+	RecordTypeAPL = "APL"
+	// RecordTypeHINFO is a RecordType enum value
+	RecordTypeHINFO = "HINFO"
 )
 
 // TTL is a structure defining the TTL of a DNS record
@@ -92,6 +104,52 @@ func (t Targets) Same(o Targets) bool {
 	return true
 }
 
+// FilterByIPFamily returns a new Targets holding only the IP addresses of the given family,
+// 4 or 6, in their original order. IPv4-mapped IPv6 addresses count as IPv4, targets which
+// are not IP addresses are left out.
+func (t Targets) FilterByIPFamily(family int) Targets {
+	filtered := Targets{}
+	for _, target := range t {
+		addr, err := netip.ParseAddr(target)
+		if err != nil {
+			continue
+		}
+		switch is4 := addr.Unmap().Is4(); {
+		case family == 4 && is4, family == 6 && !is4:
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered
+}
+
+// Dedup returns a new Targets with duplicate targets removed, keeping the first occurrence of
+// each target in its original order.
+func (t Targets) Dedup() Targets {
+	seen := make(map[string]bool, len(t))
+	deduped := make(Targets, 0, len(t))
+	for _, target := range t {
+		if !seen[target] {
+			seen[target] = true
+			deduped = append(deduped, target)
+		}
+	}
+	return deduped
+}
+
+// IsAllIPs returns true if every target is an IP address, e.g. the targets of A and AAAA records.
+// Empty targets are not considered IP addresses.
+func (t Targets) IsAllIPs() bool {
+	if len(t) == 0 {
+		return false
+	}
+	for _, target := range t {
+		if _, err := netip.ParseAddr(target); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 // IsLess should fulfill the requirement to compare two targets and choose the 'lesser' one.
 // In the past target was a simple string so simple string comparison could be used. Now we define 'less'
 // as either being the shorter list of targets or where the first entry is less.
@@ -17,6 +17,7 @@ limitations under the License.
 package endpoint
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -50,6 +51,83 @@ func TestTargetsSame(t *testing.T) {
 	}
 }
 
+func TestFilterByIPFamily(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		targets  Targets
+		family   int
+		expected Targets
+	}{
+		{"IPv4 targets are kept for family 4", Targets{"1.2.3.4", "5.6.7.8"}, 4, Targets{"1.2.3.4", "5.6.7.8"}},
+		{"IPv4 targets are dropped for family 6", Targets{"1.2.3.4", "5.6.7.8"}, 6, Targets{}},
+		{"IPv6 targets are kept for family 6", Targets{"2001:db8::1", "2001:db8::2"}, 6, Targets{"2001:db8::1", "2001:db8::2"}},
+		{"IPv6 targets are dropped for family 4", Targets{"2001:db8::1"}, 4, Targets{}},
+		{"mixed targets are filtered for family 4", Targets{"1.2.3.4", "2001:db8::1", "::ffff:5.6.7.8"}, 4, Targets{"1.2.3.4", "::ffff:5.6.7.8"}},
+		{"mixed targets are filtered for family 6", Targets{"1.2.3.4", "2001:db8::1", "::ffff:5.6.7.8"}, 6, Targets{"2001:db8::1"}},
+		{"non-IP targets are dropped", Targets{"example.org", "1.2.3.4", "2001:db8::1"}, 4, Targets{"1.2.3.4"}},
+		{"unknown families keep nothing", Targets{"1.2.3.4", "2001:db8::1"}, 5, Targets{}},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := append(Targets{}, tc.targets...)
+
+			filtered := tc.targets.FilterByIPFamily(tc.family)
+
+			if !reflect.DeepEqual(filtered, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, filtered)
+			}
+			if !reflect.DeepEqual(tc.targets, original) {
+				t.Errorf("targets must not be modified, expected %#v, got %#v", original, tc.targets)
+			}
+		})
+	}
+}
+
+func TestTargetsDedup(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		targets  Targets
+		expected Targets
+	}{
+		{"empty targets", Targets{}, Targets{}},
+		{"all duplicates", Targets{"1.2.3.4", "1.2.3.4", "1.2.3.4"}, Targets{"1.2.3.4"}},
+		{"mixed IPs and hostnames", Targets{"example.org", "1.2.3.4", "example.org", "2001:db8::1", "1.2.3.4"}, Targets{"example.org", "1.2.3.4", "2001:db8::1"}},
+		{"all IPs without duplicates", Targets{"5.6.7.8", "1.2.3.4"}, Targets{"5.6.7.8", "1.2.3.4"}},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := append(Targets{}, tc.targets...)
+
+			deduped := tc.targets.Dedup()
+
+			if !reflect.DeepEqual(deduped, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, deduped)
+			}
+			if !reflect.DeepEqual(tc.targets, original) {
+				t.Errorf("targets must not be modified, expected %#v, got %#v", original, tc.targets)
+			}
+		})
+	}
+}
+
+func TestTargetsIsAllIPs(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		targets  Targets
+		expected bool
+	}{
+		{"empty targets", Targets{}, false},
+		{"all duplicates", Targets{"1.2.3.4", "1.2.3.4"}, true},
+		{"mixed IPs and hostnames", Targets{"1.2.3.4", "example.org"}, false},
+		{"all IPs", Targets{"1.2.3.4", "2001:db8::1"}, true},
+		{"hostnames", Targets{"example.org"}, false},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			if got := tc.targets.IsAllIPs(); got != tc.expected {
+				t.Errorf("expected %v for %#v, got %v", tc.expected, tc.targets, got)
+			}
+		})
+	}
+}
+
 func TestSameFailures(t *testing.T) {
 	tests := []struct {
 		a Targets
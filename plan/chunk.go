@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// ChunkEndpoints splits the endpoints into chunks of at most size endpoints, so that large
+// change sets can be applied in bounded batches. Endpoints sharing a DNS name are never split
+// across chunks: a group larger than size is put into a chunk of its own. Groups are kept in
+// the order their first endpoint appears in. A size less than 1 returns a single chunk.
+func ChunkEndpoints(endpoints []*endpoint.Endpoint, size int) [][]*endpoint.Endpoint {
+	if len(endpoints) == 0 {
+		return nil
+	}
+	if size < 1 {
+		return [][]*endpoint.Endpoint{endpoints}
+	}
+
+	var names []string
+	groups := map[string][]*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if _, ok := groups[ep.DNSName]; !ok {
+			names = append(names, ep.DNSName)
+		}
+		groups[ep.DNSName] = append(groups[ep.DNSName], ep)
+	}
+
+	var chunks [][]*endpoint.Endpoint
+	var current []*endpoint.Endpoint
+	for _, name := range names {
+		group := groups[name]
+		if len(current) > 0 && len(current)+len(group) > size {
+			chunks = append(chunks, current)
+			current = nil
+		}
+		current = append(current, group...)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// TestChunkEndpoints tests that endpoints are split into bounded chunks keeping name groups intact.
+func TestChunkEndpoints(t *testing.T) {
+	fooA := &endpoint.Endpoint{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}}
+	fooTXT := &endpoint.Endpoint{DNSName: "foo", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"owner"}}
+	barA := &endpoint.Endpoint{DNSName: "bar", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"5.6.7.8"}}
+	bazA := &endpoint.Endpoint{DNSName: "baz", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"9.9.9.9"}}
+	bazAAAA := &endpoint.Endpoint{DNSName: "baz", RecordType: endpoint.RecordTypeAAAA, Targets: endpoint.Targets{"2001:db8::1"}}
+	bazTXT := &endpoint.Endpoint{DNSName: "baz", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"owner"}}
+
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		size      int
+		expected  [][]*endpoint.Endpoint
+	}{
+		{
+			"no endpoints",
+			nil,
+			2,
+			nil,
+		},
+		{
+			"endpoints are split by size",
+			[]*endpoint.Endpoint{fooA, barA, bazA},
+			2,
+			[][]*endpoint.Endpoint{{fooA, barA}, {bazA}},
+		},
+		{
+			"groups are not split",
+			[]*endpoint.Endpoint{barA, fooA, fooTXT},
+			2,
+			[][]*endpoint.Endpoint{{barA}, {fooA, fooTXT}},
+		},
+		{
+			"groups are collected from anywhere in the input",
+			[]*endpoint.Endpoint{fooA, barA, fooTXT},
+			2,
+			[][]*endpoint.Endpoint{{fooA, fooTXT}, {barA}},
+		},
+		{
+			"groups larger than size get a chunk of their own",
+			[]*endpoint.Endpoint{fooA, bazA, bazAAAA, bazTXT, barA},
+			2,
+			[][]*endpoint.Endpoint{{fooA}, {bazA, bazAAAA, bazTXT}, {barA}},
+		},
+		{
+			"size below one returns a single chunk",
+			[]*endpoint.Endpoint{fooA, barA, bazA},
+			0,
+			[][]*endpoint.Endpoint{{fooA, barA, bazA}},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			chunks := ChunkEndpoints(tc.endpoints, tc.size)
+			assert.Equal(t, tc.expected, chunks)
+
+			for _, chunk := range chunks {
+				if len(chunk) > tc.size && tc.size > 0 {
+					assert.Len(t, groupNames(chunk), 1, "only a single group may exceed the chunk size")
+				}
+			}
+			seen := map[string]int{}
+			for i, chunk := range chunks {
+				for name := range groupNames(chunk) {
+					_, ok := seen[name]
+					assert.False(t, ok, "group %s is split across chunks %d and %d", name, seen[name], i)
+					seen[name] = i
+				}
+			}
+		})
+	}
+}
+
+func groupNames(chunk []*endpoint.Endpoint) map[string]bool {
+	names := map[string]bool{}
+	for _, ep := range chunk {
+		names[ep.DNSName] = true
+	}
+	return names
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sort"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// aggregateSource is a Source that adds a single A record whose targets are the union
+// of the A record targets of its wrapped source.
+type aggregateSource struct {
+	source  Source
+	dnsName string
+}
+
+// NewAggregateSource creates a new aggregateSource wrapping the provided Source.
+func NewAggregateSource(source Source, dnsName string) Source {
+	return &aggregateSource{source: source, dnsName: dnsName}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them together with
+// the aggregate endpoint. The aggregate is rebuilt from scratch on every call, so that it
+// is always replaced as a whole and never reflects a partially applied change.
+// No aggregate endpoint is returned if there are no A record targets.
+func (ms *aggregateSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := endpoint.Targets{}
+	for _, ep := range endpoints {
+		if ep.RecordType == endpoint.RecordTypeA {
+			targets = append(targets, ep.Targets...)
+		}
+	}
+	targets = targets.Dedup()
+
+	if len(targets) == 0 {
+		return endpoints, nil
+	}
+
+	sort.Sort(targets)
+	return append(endpoints, endpoint.NewEndpoint(ms.dnsName, endpoint.RecordTypeA, targets...)), nil
+}
+
+func (ms *aggregateSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that aggregateSource is a Source
+var _ Source = &aggregateSource{}
+
+func TestAggregateSource(t *testing.T) {
+	t.Run("Endpoints", testAggregateSourceEndpoints)
+	t.Run("Updates", testAggregateSourceUpdates)
+}
+
+// testAggregateSourceEndpoints tests that the aggregate endpoint unites all A record targets.
+func testAggregateSourceEndpoints(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"no endpoints return no aggregate",
+			[]*endpoint.Endpoint{},
+			[]*endpoint.Endpoint{},
+		},
+		{
+			"non A endpoints return no aggregate",
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+			},
+		},
+		{
+			"A endpoints are aggregated",
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "node2", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8", "1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "node2", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8", "1.2.3.4"}},
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewAggregateSource(mockSource, "nodes.example.org")
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+// testAggregateSourceUpdates tests that added and removed nodes replace the aggregate as a whole.
+func testAggregateSourceUpdates(t *testing.T) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "node1", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		{DNSName: "node2", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+	}, nil).Once()
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "node2", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+		{DNSName: "node3", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}},
+	}, nil).Once()
+
+	source := NewAggregateSource(mockSource, "nodes.example.org")
+
+	first, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	require.Len(t, first, 3)
+	aggregate := first[2]
+	assert.Equal(t, endpoint.Targets{"1.1.1.1", "2.2.2.2"}, aggregate.Targets)
+
+	second, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	require.Len(t, second, 3)
+	assert.Equal(t, endpoint.Targets{"2.2.2.2", "3.3.3.3"}, second[2].Targets)
+
+	// the previously returned aggregate must not have been patched in place.
+	assert.Equal(t, endpoint.Targets{"1.1.1.1", "2.2.2.2"}, aggregate.Targets)
+
+	mockSource.AssertExpectations(t)
+}
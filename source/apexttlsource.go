@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// apexTTLSource is a Source that sets a dedicated TTL on the endpoints of its wrapped source
+// at the apex of one of the configured domains.
+type apexTTLSource struct {
+	source  Source
+	apexes  map[string]bool
+	apexTTL endpoint.TTL
+}
+
+// NewApexTTLSource creates a new apexTTLSource wrapping the provided Source.
+func NewApexTTLSource(source Source, apexDomains []string, apexTTL endpoint.TTL) Source {
+	apexes := make(map[string]bool, len(apexDomains))
+	for _, domain := range apexDomains {
+		apexes[normalizeDNSNameCase(domain)] = true
+	}
+	return &apexTTLSource{source: source, apexes: apexes, apexTTL: apexTTL}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them with copies of the apex
+// endpoints carrying the apex TTL. Names are compared ignoring case and trailing dots, endpoints
+// of subdomains are passed through untouched.
+func (ms *apexTTLSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ms.apexes[normalizeDNSNameCase(ep.DNSName)] && ep.RecordTTL != ms.apexTTL {
+			ep = ep.DeepCopy()
+			ep.RecordTTL = ms.apexTTL
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *apexTTLSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
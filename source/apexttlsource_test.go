@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that apexTTLSource is a Source
+var _ Source = &apexTTLSource{}
+
+func TestApexTTLSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"apex endpoints get the apex TTL",
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 300},
+				{DNSName: "Example.COM.", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 3600},
+				{DNSName: "Example.COM.", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}, RecordTTL: 3600},
+			},
+		},
+		{
+			"subdomain endpoints are left alone",
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 300},
+				{DNSName: "www.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"example.org"}, RecordTTL: 300},
+				{DNSName: "foo.example.com", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+				{DNSName: "example.net", RecordType: "A", Targets: endpoint.Targets{"9.9.9.9"}, RecordTTL: 60},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 3600},
+				{DNSName: "www.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"example.org"}, RecordTTL: 300},
+				{DNSName: "foo.example.com", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+				{DNSName: "example.net", RecordType: "A", Targets: endpoint.Targets{"9.9.9.9"}, RecordTTL: 60},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].RecordTTL
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewApexTTLSource(mockSource, []string{"example.org", "example.com."}, 3600)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].RecordTTL, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// cachedSource is a Source that memoizes the endpoints of its wrapped source for a while.
+type cachedSource struct {
+	source Source
+	ttl    time.Duration
+	now    func() time.Time
+
+	mutex       sync.Mutex
+	endpoints   []*endpoint.Endpoint
+	hasLastGood bool
+	cached      bool
+	expires     time.Time
+}
+
+// NewCachedSource creates a new cachedSource wrapping the provided Source.
+func NewCachedSource(source Source, ttl time.Duration) Source {
+	return &cachedSource{source: source, ttl: ttl, now: time.Now}
+}
+
+// Endpoints returns the endpoints of the last successful call to its wrapped source until
+// the ttl elapses, and collects them again afterwards. If that fails while endpoints of an
+// earlier call are known, the error is logged and the known endpoints are returned.
+// The cache holds copies of the endpoints, so callers may modify the returned endpoints.
+func (ms *cachedSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if ms.cached && ms.now().Before(ms.expires) {
+		return copyEndpoints(ms.endpoints), nil
+	}
+
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		if !ms.hasLastGood {
+			return nil, err
+		}
+		log.Errorf("Failed to refresh cached endpoints, using the last known ones: %v", err)
+		return copyEndpoints(ms.endpoints), nil
+	}
+
+	ms.endpoints = copyEndpoints(endpoints)
+	ms.hasLastGood = true
+	ms.cached = true
+	ms.expires = ms.now().Add(ms.ttl)
+	return endpoints, nil
+}
+
+// AddEventHandler adds the handler to the wrapped source, invalidating the cache before
+// the handler runs so that event driven syncs see fresh endpoints.
+func (ms *cachedSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, func() {
+		ms.invalidate()
+		handler()
+	})
+}
+
+// invalidate makes the next call to Endpoints collect endpoints from the wrapped source.
+func (ms *cachedSource) invalidate() {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.cached = false
+}
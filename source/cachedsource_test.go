@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Validates that cachedSource is a Source
+var _ Source = &cachedSource{}
+
+// countingSource returns fixed endpoints, counts calls and lets tests trigger its event handlers.
+type countingSource struct {
+	endpoints []*endpoint.Endpoint
+	err       error
+	calls     int
+	handlers  []func()
+}
+
+func (s *countingSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.endpoints, nil
+}
+
+func (s *countingSource) AddEventHandler(ctx context.Context, handler func()) {
+	s.handlers = append(s.handlers, handler)
+}
+
+func (s *countingSource) trigger() {
+	for _, handler := range s.handlers {
+		handler()
+	}
+}
+
+func TestCachedSource(t *testing.T) {
+	t.Run("Caching", testCachedSourceCaching)
+	t.Run("Error", testCachedSourceError)
+	t.Run("Invalidation", testCachedSourceInvalidation)
+	t.Run("Aliasing", testCachedSourceAliasing)
+	t.Run("EmptyLastGood", testCachedSourceEmptyLastGood)
+}
+
+func newTestCachedSource(inner Source, now *time.Time) *cachedSource {
+	source := NewCachedSource(inner, time.Minute).(*cachedSource)
+	source.now = func() time.Time { return *now }
+	return source
+}
+
+// testCachedSourceCaching tests that endpoints are cached until the ttl elapses.
+func testCachedSourceCaching(t *testing.T) {
+	inner := &countingSource{endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")}}
+	now := time.Now()
+	source := newTestCachedSource(inner, &now)
+
+	for i := 0; i < 3; i++ {
+		endpoints, err := source.Endpoints(context.Background())
+		require.NoError(t, err)
+		validateEndpoints(t, endpoints, []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")})
+	}
+	assert.Equal(t, 1, inner.calls)
+
+	now = now.Add(2 * time.Minute)
+	_, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls, "expired endpoints must be refreshed")
+}
+
+// testCachedSourceError tests that refresh errors fall back to the last known endpoints.
+func testCachedSourceError(t *testing.T) {
+	inner := &countingSource{err: errors.New("boom")}
+	now := time.Now()
+	source := newTestCachedSource(inner, &now)
+
+	_, err := source.Endpoints(context.Background())
+	require.Error(t, err, "errors must be returned without known endpoints")
+
+	inner.err = nil
+	inner.endpoints = []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")}
+	_, err = source.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	inner.err = errors.New("boom")
+	now = now.Add(2 * time.Minute)
+	endpoints, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")})
+	assert.Equal(t, 3, inner.calls)
+}
+
+// testCachedSourceInvalidation tests that events of the wrapped source invalidate the cache.
+func testCachedSourceInvalidation(t *testing.T) {
+	inner := &countingSource{endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")}}
+	now := time.Now()
+	source := newTestCachedSource(inner, &now)
+
+	handled := 0
+	source.AddEventHandler(context.Background(), func() { handled++ })
+
+	_, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	inner.trigger()
+	assert.Equal(t, 1, handled)
+
+	_, err = source.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls, "events must bypass the cache")
+}
+
+// testCachedSourceAliasing tests that callers modifying returned endpoints do not modify the cache.
+func testCachedSourceAliasing(t *testing.T) {
+	inner := &countingSource{endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")}}
+	now := time.Now()
+	source := newTestCachedSource(inner, &now)
+
+	endpoints, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	endpoints[0].Targets = endpoint.Targets{"5.6.7.8"}
+
+	endpoints, err = source.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")})
+	endpoints[0].Targets = endpoint.Targets{"5.6.7.8"}
+
+	inner.err = errors.New("boom")
+	now = now.Add(2 * time.Minute)
+	endpoints, err = source.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")})
+}
+
+// testCachedSourceEmptyLastGood tests that an empty result counts as last known endpoints.
+func testCachedSourceEmptyLastGood(t *testing.T) {
+	inner := &countingSource{}
+	now := time.Now()
+	source := newTestCachedSource(inner, &now)
+
+	_, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	inner.err = errors.New("boom")
+	now = now.Add(2 * time.Minute)
+	endpoints, err := source.Endpoints(context.Background())
+	require.NoError(t, err, "an empty result is a valid last known result")
+	assert.Empty(t, endpoints)
+}
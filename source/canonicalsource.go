@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// canonicalSource is a Source that normalizes, deduplicates and sorts the endpoints of its
+// wrapped source in a single pass, replacing a chain of normalizeSource and dedupSource.
+type canonicalSource struct {
+	source Source
+}
+
+// NewCanonicalSource creates a new canonicalSource wrapping the provided Source.
+func NewCanonicalSource(source Source) Source {
+	return &canonicalSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them with their DNS name
+// normalized as by normalizeSource, without the duplicates dropped by dedupSource, ordered by
+// DNS name, record type and set identifier. Endpoints whose DNS name is already normalized
+// are returned as they are, all others are copied.
+func (ms *canonicalSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	collected := make(map[string]bool, len(endpoints))
+	for _, ep := range endpoints {
+		dnsName := normalizeDNSName(ep.DNSName)
+		identifier := dnsName + " / " + ep.SetIdentifier + " / " + ep.Targets.String()
+		if collected[identifier] {
+			log.Debugf("Removing duplicate endpoint %s", ep)
+			continue
+		}
+		collected[identifier] = true
+
+		if dnsName != ep.DNSName {
+			ep = ep.DeepCopy()
+			ep.DNSName = dnsName
+		}
+		result = append(result, ep)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].DNSName != result[j].DNSName {
+			return result[i].DNSName < result[j].DNSName
+		}
+		if result[i].RecordType != result[j].RecordType {
+			return result[i].RecordType < result[j].RecordType
+		}
+		return result[i].SetIdentifier < result[j].SetIdentifier
+	})
+
+	return result, nil
+}
+
+func (ms *canonicalSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that canonicalSource is a Source
+var _ Source = &canonicalSource{}
+
+// chainedCanonicalEndpoints returns the endpoints of the chained middlewares replaced by
+// canonicalSource, sorted the same way.
+func chainedCanonicalEndpoints(t testing.TB, source Source) []*endpoint.Endpoint {
+	endpoints, err := NewDedupSource(NewNormalizeSource(source)).Endpoints(context.Background())
+	require.NoError(t, err)
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		a, b := endpoints[i], endpoints[j]
+		return a.DNSName+"\x00"+a.RecordType+"\x00"+a.SetIdentifier < b.DNSName+"\x00"+b.RecordType+"\x00"+b.SetIdentifier
+	})
+	return endpoints
+}
+
+// randomCanonicalEndpoints returns n endpoints with many duplicates and names needing normalization.
+func randomCanonicalEndpoints(n int) []*endpoint.Endpoint {
+	rnd := rand.New(rand.NewSource(1))
+	names := []string{"foo.example.org", "Foo.Example.org.", "bar..example.org", "BAR.example.org", "baz.example.org"}
+	endpoints := make([]*endpoint.Endpoint, 0, n)
+	for i := 0; i < n; i++ {
+		ep := endpoint.NewEndpoint(
+			fmt.Sprintf("%d.%s", rnd.Intn(n/4+1), names[rnd.Intn(len(names))]),
+			[]string{"A", "AAAA", "TXT"}[rnd.Intn(3)],
+			fmt.Sprintf("10.0.0.%d", rnd.Intn(4)),
+		)
+		ep.SetIdentifier = []string{"", "a", "b"}[rnd.Intn(3)]
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+func TestCanonicalSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"names are normalized, duplicates removed and endpoints sorted",
+			[]*endpoint.Endpoint{
+				{DNSName: "Foo.Example.org.", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, SetIdentifier: "b"},
+				{DNSName: "bar..example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, SetIdentifier: "a"},
+				{DNSName: "FOO.example.org", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, SetIdentifier: "a"},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, SetIdentifier: "b"},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+			},
+		},
+		{
+			"no endpoints",
+			[]*endpoint.Endpoint{},
+			[]*endpoint.Endpoint{},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			endpoints, err := NewCanonicalSource(mockSource).Endpoints(context.Background())
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, endpoints)
+			require.Equal(t, original, tc.endpoints, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCanonicalSourceEqualsChainedMiddlewares(t *testing.T) {
+	for _, n := range []int{1, 10, 100, 1000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(randomCanonicalEndpoints(n), nil)
+
+			endpoints, err := NewCanonicalSource(mockSource).Endpoints(context.Background())
+			require.NoError(t, err)
+
+			require.Equal(t, chainedCanonicalEndpoints(t, mockSource), endpoints)
+		})
+	}
+}
+
+func BenchmarkCanonicalSource(b *testing.B) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(randomCanonicalEndpoints(10000), nil)
+
+	b.Run("single pass", func(b *testing.B) {
+		source := NewCanonicalSource(mockSource)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err := source.Endpoints(context.Background())
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("chained", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			chainedCanonicalEndpoints(b, mockSource)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sort"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// dependencyOrderSource is a Source that returns the endpoints of its wrapped source in the
+// order they must be created in, for providers applying changes one by one.
+type dependencyOrderSource struct {
+	source Source
+}
+
+// NewDependencyOrderSource creates a new dependencyOrderSource wrapping the provided Source.
+func NewDependencyOrderSource(source Source) Source {
+	return &dependencyOrderSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them in creation order.
+func (ms *dependencyOrderSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return CreationOrder(endpoints), nil
+}
+
+func (ms *dependencyOrderSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
+
+// dependencyRank returns the position of a record type in creation order: TXT records guarding
+// the ownership of other records come first, CNAME records pointing to other records come last.
+func dependencyRank(recordType string) int {
+	switch recordType {
+	case endpoint.RecordTypeTXT:
+		return 0
+	case endpoint.RecordTypeCNAME:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// CreationOrder returns a copy of endpoints ordered so that every record is created after the
+// records it depends on. The order of endpoints of the same rank is preserved.
+func CreationOrder(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	result := make([]*endpoint.Endpoint, len(endpoints))
+	copy(result, endpoints)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return dependencyRank(result[i].RecordType) < dependencyRank(result[j].RecordType)
+	})
+
+	return result
+}
+
+// DeletionOrder returns a copy of endpoints ordered so that every record is deleted before the
+// records it depends on, which is the reverse of CreationOrder.
+func DeletionOrder(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	result := CreationOrder(endpoints)
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
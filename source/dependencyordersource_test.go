@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that dependencyOrderSource is a Source
+var _ Source = &dependencyOrderSource{}
+
+func newDependencyOrderEndpoints() []*endpoint.Endpoint {
+	return []*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeCNAME, "foo.example.org"),
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default"),
+		endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "5.6.7.8"),
+		endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default"),
+	}
+}
+
+// recordsOf returns the DNS names and record types of endpoints in order.
+func recordsOf(endpoints []*endpoint.Endpoint) []string {
+	records := make([]string, 0, len(endpoints))
+	for _, ep := range endpoints {
+		records = append(records, ep.DNSName+" "+ep.RecordType)
+	}
+	return records
+}
+
+func TestDependencyOrderSource(t *testing.T) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(newDependencyOrderEndpoints(), nil)
+
+	endpoints, err := NewDependencyOrderSource(mockSource).Endpoints(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"foo.example.org TXT",
+		"bar.example.org TXT",
+		"foo.example.org A",
+		"bar.example.org A",
+		"www.example.org CNAME",
+	}, recordsOf(endpoints))
+
+	mockSource.AssertExpectations(t)
+}
+
+func TestCreationOrder(t *testing.T) {
+	endpoints := newDependencyOrderEndpoints()
+
+	assert.Equal(t, []string{
+		"foo.example.org TXT",
+		"bar.example.org TXT",
+		"foo.example.org A",
+		"bar.example.org A",
+		"www.example.org CNAME",
+	}, recordsOf(CreationOrder(endpoints)))
+	assert.Equal(t, recordsOf(newDependencyOrderEndpoints()), recordsOf(endpoints), "input must not be reordered")
+}
+
+func TestDeletionOrder(t *testing.T) {
+	assert.Equal(t, []string{
+		"www.example.org CNAME",
+		"bar.example.org A",
+		"foo.example.org A",
+		"bar.example.org TXT",
+		"foo.example.org TXT",
+	}, recordsOf(DeletionOrder(newDependencyOrderEndpoints())))
+}
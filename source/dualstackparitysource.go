@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// DualStackParityMode defines how dualStackParitySource handles names with records of one IP family only.
+type DualStackParityMode string
+
+const (
+	// DualStackParityWarn logs a warning and keeps the records.
+	DualStackParityWarn DualStackParityMode = "warn"
+	// DualStackParityDrop logs a warning and drops the A or AAAA records.
+	DualStackParityDrop DualStackParityMode = "drop"
+)
+
+// dualStackParitySource is a Source that checks that every DNS name of its wrapped source with
+// an A record also has an AAAA record and vice versa.
+type dualStackParitySource struct {
+	source Source
+	mode   DualStackParityMode
+}
+
+// NewDualStackParitySource creates a new dualStackParitySource wrapping the provided Source.
+func NewDualStackParitySource(source Source, mode DualStackParityMode) Source {
+	return &dualStackParitySource{source: source, mode: mode}
+}
+
+// dualStackKey identifies the names checked for parity. Records with different set
+// identifiers are checked separately.
+type dualStackKey struct {
+	dnsName       string
+	setIdentifier string
+}
+
+// Endpoints collects endpoints from its wrapped source and reports the names having either A or
+// AAAA records but not both. In drop mode the address records of these names are removed, all
+// other records are always passed through.
+func (ms *dualStackParitySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	families := map[dualStackKey]map[string]bool{}
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA {
+			continue
+		}
+		key := dualStackKey{dnsName: ep.DNSName, setIdentifier: ep.SetIdentifier}
+		if families[key] == nil {
+			families[key] = map[string]bool{}
+		}
+		families[key][ep.RecordType] = true
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		key := dualStackKey{dnsName: ep.DNSName, setIdentifier: ep.SetIdentifier}
+		if types, ok := families[key]; ok && len(types) == 1 {
+			if types[ep.RecordType] {
+				log.Warnf("Endpoint %s has no record of the other IP family", ep)
+				if ms.mode == DualStackParityDrop {
+					continue
+				}
+			}
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *dualStackParitySource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
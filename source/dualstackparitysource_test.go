@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that dualStackParitySource is a Source
+var _ Source = &dualStackParitySource{}
+
+func TestDualStackParitySource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		mode      DualStackParityMode
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+		warnings  int
+	}{
+		{
+			"names with both families are kept",
+			DualStackParityDrop,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+				{DNSName: "bar.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"foo.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+				{DNSName: "bar.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"foo.example.org"}},
+			},
+			0,
+		},
+		{
+			"warn mode keeps names with one family",
+			DualStackParityWarn,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::2"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::2"}},
+			},
+			2,
+		},
+		{
+			"drop mode removes address records of names with one family",
+			DualStackParityDrop,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"info"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+				{DNSName: "bar.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::2"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"info"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+				{DNSName: "bar.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::2"}},
+			},
+			1,
+		},
+		{
+			"set identifiers are checked separately",
+			DualStackParityDrop,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "eu", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", SetIdentifier: "us", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+			[]*endpoint.Endpoint{},
+			2,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewDualStackParitySource(mockSource, tc.mode)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+
+			warnings := 0
+			for _, entry := range hook.AllEntries() {
+				if strings.Contains(entry.Message, "has no record of the other IP family") {
+					warnings++
+				}
+			}
+			assert.Equal(t, tc.warnings, warnings)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// fallbackSource is a Source that serves the endpoints of a secondary source while its primary
+// source fails, e.g. static endpoints from a file while the API server is unreachable.
+type fallbackSource struct {
+	primary   Source
+	secondary Source
+}
+
+// NewFallbackSource creates a new fallbackSource wrapping the provided Sources.
+func NewFallbackSource(primary, secondary Source) Source {
+	return &fallbackSource{primary: primary, secondary: secondary}
+}
+
+// Endpoints returns the endpoints of the primary source. Only if it fails, the failure is logged
+// and the endpoints of the secondary source are returned. If both fail, both errors are returned.
+func (ms *fallbackSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.primary.Endpoints(ctx)
+	if err == nil {
+		return endpoints, nil
+	}
+	log.Errorf("Failed to get endpoints from primary source, falling back to secondary source: %v", err)
+
+	endpoints, secondaryErr := ms.secondary.Endpoints(ctx)
+	if secondaryErr != nil {
+		return nil, fmt.Errorf("primary source failed: %v, secondary source failed: %w", err, secondaryErr)
+	}
+	return endpoints, nil
+}
+
+// AddEventHandler registers the handler with both sources.
+func (ms *fallbackSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.primary.AddEventHandler(ctx, handler)
+	ms.secondary.AddEventHandler(ctx, handler)
+}
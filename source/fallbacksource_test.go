@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Validates that fallbackSource is a Source
+var _ Source = &fallbackSource{}
+
+func TestFallbackSource(t *testing.T) {
+	t.Run("PrimarySuccess", testFallbackSourcePrimarySuccess)
+	t.Run("PrimaryError", testFallbackSourcePrimaryError)
+	t.Run("BothError", testFallbackSourceBothError)
+	t.Run("EventHandler", testFallbackSourceEventHandler)
+}
+
+// testFallbackSourcePrimarySuccess tests that the secondary source isn't asked while the primary succeeds.
+func testFallbackSourcePrimarySuccess(t *testing.T) {
+	primary := &countingSource{endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")}}
+	secondary := &countingSource{endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "5.6.7.8")}}
+
+	endpoints, err := NewFallbackSource(primary, secondary).Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")})
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 0, secondary.calls)
+}
+
+// testFallbackSourcePrimaryError tests that the secondary source is served when the primary fails.
+func testFallbackSourcePrimaryError(t *testing.T) {
+	primary := &countingSource{err: errors.New("api unreachable")}
+	secondary := &countingSource{endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "5.6.7.8")}}
+
+	endpoints, err := NewFallbackSource(primary, secondary).Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "5.6.7.8")})
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+}
+
+// testFallbackSourceBothError tests that both errors are returned when both sources fail.
+func testFallbackSourceBothError(t *testing.T) {
+	primaryErr := errors.New("api unreachable")
+	secondaryErr := errors.New("file missing")
+
+	_, err := NewFallbackSource(&countingSource{err: primaryErr}, &countingSource{err: secondaryErr}).Endpoints(context.Background())
+	require.Error(t, err)
+
+	assert.ErrorIs(t, err, secondaryErr)
+	assert.Contains(t, err.Error(), "api unreachable")
+	assert.Contains(t, err.Error(), "file missing")
+}
+
+// testFallbackSourceEventHandler tests that the handler is registered with both sources.
+func testFallbackSourceEventHandler(t *testing.T) {
+	primary := &countingSource{}
+	secondary := &countingSource{}
+
+	calls := 0
+	NewFallbackSource(primary, secondary).AddEventHandler(context.Background(), func() { calls++ })
+
+	primary.trigger()
+	secondary.trigger()
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// knownRecordTypes lists the record types accepted in endpoint files.
+var knownRecordTypes = map[string]bool{
+	endpoint.RecordTypeA:          true,
+	endpoint.RecordTypeAAAA:       true,
+	endpoint.RecordTypeCNAME:      true,
+	endpoint.RecordTypeTXT:        true,
+	endpoint.RecordTypeSRV:        true,
+	endpoint.RecordTypeNS:         true,
+	endpoint.RecordTypePTR:        true,
+	endpoint.RecordTypeCERT:       true,
+	endpoint.RecordTypeDS:         true,
+	endpoint.RecordTypeOPENPGPKEY: true,
+	endpoint.RecordTypeAPL:        true,
+	endpoint.RecordTypeHINFO:      true,
+}
+
+// fileSource is a Source that returns static endpoints read from a file.
+type fileSource struct {
+	endpoints []*endpoint.Endpoint
+}
+
+// NewFileSource creates a new fileSource returning the endpoints listed in the YAML or JSON
+// file at the given path. The file is read once and must hold a list of endpoints using the
+// field names of the DNSEndpoint resource, e.g. dnsName, recordType, targets and recordTTL.
+func NewFileSource(path string) (Source, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var endpoints []*endpoint.Endpoint
+	if err := yaml.NewYAMLOrJSONDecoder(file, 4096).Decode(&endpoints); err != nil {
+		return nil, fmt.Errorf("failed to parse endpoints file %s: %w", path, err)
+	}
+
+	for i, ep := range endpoints {
+		if err := validateFileEndpoint(ep); err != nil {
+			return nil, fmt.Errorf("invalid endpoint %d in %s: %w", i, path, err)
+		}
+	}
+
+	return &fileSource{endpoints: endpoints}, nil
+}
+
+// validateFileEndpoint checks that an endpoint read from a file can be published.
+func validateFileEndpoint(ep *endpoint.Endpoint) error {
+	if ep == nil || ep.DNSName == "" {
+		return fmt.Errorf("missing DNS name")
+	}
+	if !knownRecordTypes[ep.RecordType] {
+		return fmt.Errorf("unknown record type %q of %s", ep.RecordType, ep.DNSName)
+	}
+	if len(ep.Targets) == 0 {
+		return fmt.Errorf("missing targets of %s", ep.DNSName)
+	}
+	for _, t := range ep.Targets {
+		ip := net.ParseIP(t)
+		switch {
+		case ep.RecordType == endpoint.RecordTypeA && (ip == nil || ip.To4() == nil):
+			return fmt.Errorf("target %q of %s is not an IPv4 address", t, ep.DNSName)
+		case ep.RecordType == endpoint.RecordTypeAAAA && (ip == nil || ip.To4() != nil):
+			return fmt.Errorf("target %q of %s is not an IPv6 address", t, ep.DNSName)
+		case ep.RecordType == endpoint.RecordTypeSRV:
+			if err := validateSRVTarget(t); err != nil {
+				return fmt.Errorf("%s: %w", ep.DNSName, err)
+			}
+		case ep.RecordType == endpoint.RecordTypeHINFO:
+			if _, err := parseHINFOData(t); err != nil {
+				return fmt.Errorf("invalid HINFO target %q of %s: %w", t, ep.DNSName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Endpoints returns copies of the endpoints read from the file.
+func (fs *fileSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	result := make([]*endpoint.Endpoint, 0, len(fs.endpoints))
+	for _, ep := range fs.endpoints {
+		result = append(result, ep.DeepCopy())
+	}
+	return result, nil
+}
+
+// AddEventHandler does nothing as the file is only read once.
+func (fs *fileSource) AddEventHandler(ctx context.Context, handler func()) {
+}
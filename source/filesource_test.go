@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Validates that fileSource is a Source
+var _ Source = &fileSource{}
+
+func TestFileSource(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		content     string
+		expected    []*endpoint.Endpoint
+		expectError string
+	}{
+		{
+			title: "valid YAML file with multiple records",
+			content: `
+- dnsName: foo.example.org
+  recordType: A
+  targets: ["1.2.3.4", "5.6.7.8"]
+  recordTTL: 300
+  labels:
+    owner: static
+- dnsName: foo.example.org
+  recordType: AAAA
+  targets: ["2001:db8::1"]
+- dnsName: www.example.org
+  recordType: CNAME
+  targets: ["foo.example.org"]
+`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}, RecordTTL: 300, Labels: endpoint.Labels{"owner": "static"}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+				{DNSName: "www.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"foo.example.org"}},
+			},
+		},
+		{
+			title:   "valid JSON file",
+			content: `[{"dnsName": "foo.example.org", "recordType": "TXT", "targets": ["hello"]}]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"hello"}},
+			},
+		},
+		{
+			title: "unknown record type",
+			content: `
+- dnsName: foo.example.org
+  recordType: MX
+  targets: ["10 mail.example.org"]
+`,
+			expectError: `unknown record type "MX" of foo.example.org`,
+		},
+		{
+			title: "A record with hostname target",
+			content: `
+- dnsName: foo.example.org
+  recordType: A
+  targets: ["bar.example.org"]
+`,
+			expectError: `target "bar.example.org" of foo.example.org is not an IPv4 address`,
+		},
+		{
+			title: "AAAA record with IPv4 target",
+			content: `
+- dnsName: foo.example.org
+  recordType: AAAA
+  targets: ["1.2.3.4"]
+`,
+			expectError: `target "1.2.3.4" of foo.example.org is not an IPv6 address`,
+		},
+		{
+			title: "SRV record with invalid port",
+			content: `
+- dnsName: _http._tcp.example.org
+  recordType: SRV
+  targets: ["0 50 0 foo.example.org"]
+`,
+			expectError: `_http._tcp.example.org: invalid port "0" of SRV target "0 50 0 foo.example.org"`,
+		},
+		{
+			title: "HINFO record",
+			content: `
+- dnsName: foo.example.org
+  recordType: HINFO
+  targets: ['"amd64" "Ubuntu 22.04"']
+`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "HINFO", Targets: endpoint.Targets{`"amd64" "Ubuntu 22.04"`}},
+			},
+		},
+		{
+			title: "HINFO record without os",
+			content: `
+- dnsName: foo.example.org
+  recordType: HINFO
+  targets: ["amd64"]
+`,
+			expectError: `invalid HINFO target "amd64" of foo.example.org`,
+		},
+		{
+			title:       "malformed file",
+			content:     `dnsName: [foo`,
+			expectError: "failed to parse endpoints file",
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "endpoints.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			source, err := NewFileSource(path)
+			if tc.expectError != "" {
+				require.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+func TestFileSourceMissingFile(t *testing.T) {
+	_, err := NewFileSource(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// flattenCNAMESource is a Source that replaces the CNAME endpoints of its wrapped source
+// with the A and AAAA records their targets resolve to.
+type flattenCNAMESource struct {
+	source Source
+	lookup hostLookup
+	cache  *resolutionCache
+}
+
+// FlushableSource is a Source caching state that can be dropped on demand.
+type FlushableSource interface {
+	Source
+	// Flush drops all cached state.
+	Flush()
+}
+
+// hostLookup returns the addresses of a host and how long they may be cached, zero if unknown.
+type hostLookup func(ctx context.Context, host string) ([]net.IP, time.Duration, error)
+
+// resolvConfPath is the resolver configuration used when no nameserver is given.
+const resolvConfPath = "/etc/resolv.conf"
+
+// NewFlattenCNAMESource creates a new flattenCNAMESource wrapping the provided Source.
+// CNAME targets are resolved by querying the given nameserver, e.g. "10.0.0.10:53", or the
+// nameservers of /etc/resolv.conf if empty. Addresses are cached for the TTL of the answer
+// clamped between minTTL and maxTTL. The maxTTL must be positive and not below minTTL.
+func NewFlattenCNAMESource(source Source, nameserver string, minTTL, maxTTL time.Duration) (FlushableSource, error) {
+	if maxTTL <= 0 || minTTL < 0 || minTTL > maxTTL {
+		return nil, fmt.Errorf("invalid TTL range [%s, %s] for resolved addresses", minTTL, maxTTL)
+	}
+
+	nameservers := []string{nameserver}
+	if nameserver == "" {
+		config, err := dns.ClientConfigFromFile(resolvConfPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read nameservers: %w", err)
+		}
+		nameservers = nameservers[:0]
+		for _, server := range config.Servers {
+			nameservers = append(nameservers, net.JoinHostPort(server, config.Port))
+		}
+	}
+
+	return &flattenCNAMESource{source: source, lookup: nameserverLookup(nameservers), cache: newResolutionCache(minTTL, maxTTL)}, nil
+}
+
+// Endpoints collects endpoints from its wrapped source and replaces each CNAME endpoint with
+// A and AAAA endpoints of the same name and TTL holding the addresses of its targets. If a
+// target can't be resolved, the CNAME endpoint is kept. All other endpoints are passed through.
+func (ms *flattenCNAMESource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeCNAME {
+			result = append(result, ep)
+			continue
+		}
+
+		flattened, err := ms.flatten(ctx, ep)
+		if err != nil {
+			log.Warnf("Keeping CNAME endpoint %s: %v", ep, err)
+			result = append(result, ep)
+			continue
+		}
+		result = append(result, flattened...)
+	}
+
+	return result, nil
+}
+
+// flatten returns copies of the CNAME endpoint turned into A and AAAA endpoints holding the
+// sorted and deduplicated addresses of its targets.
+func (ms *flattenCNAMESource) flatten(ctx context.Context, ep *endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	var addrs []netip.Addr
+	for _, t := range ep.Targets {
+		ips, err := ms.resolve(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if addr, ok := netip.AddrFromSlice(ip); ok {
+				addrs = append(addrs, addr.Unmap())
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", ep.Targets)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Less(addrs[j]) })
+
+	var flattened []*endpoint.Endpoint
+	for _, recordType := range []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA} {
+		targets := endpoint.Targets{}
+		for _, addr := range addrs {
+			if addr.Is4() == (recordType == endpoint.RecordTypeA) {
+				targets = append(targets, addr.String())
+			}
+		}
+		targets = targets.Dedup()
+		if len(targets) == 0 {
+			continue
+		}
+		flat := ep.DeepCopy()
+		flat.RecordType = recordType
+		flat.Targets = targets
+		flattened = append(flattened, flat)
+	}
+	return flattened, nil
+}
+
+// resolve returns the addresses of the host, from the cache if they are known.
+func (ms *flattenCNAMESource) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ips, ok := ms.cache.get(host); ok {
+		return ips, nil
+	}
+	ips, ttl, err := ms.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) > 0 {
+		ms.cache.set(host, ips, ttl)
+	}
+	return ips, nil
+}
+
+// Flush drops all cached addresses, so that the next call to Endpoints resolves them again.
+func (ms *flattenCNAMESource) Flush() {
+	ms.cache.Flush()
+}
+
+// nameserverLookup returns a hostLookup querying the A and AAAA records of hosts from the
+// first of the nameservers that answers. Truncated answers are retried over TCP. The returned
+// TTL is the lowest TTL of the answer records.
+func nameserverLookup(nameservers []string) hostLookup {
+	udp := &dns.Client{Net: "udp"}
+	tcp := &dns.Client{Net: "tcp"}
+	return func(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+		var ips []net.IP
+		var ttl uint32
+		for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			msg := new(dns.Msg)
+			msg.SetQuestion(dns.Fqdn(host), qtype)
+
+			var resp *dns.Msg
+			var err error
+			for _, nameserver := range nameservers {
+				resp, _, err = udp.ExchangeContext(ctx, msg, nameserver)
+				if err == nil && resp.Truncated {
+					resp, _, err = tcp.ExchangeContext(ctx, msg, nameserver)
+				}
+				if err == nil {
+					break
+				}
+			}
+			if err != nil {
+				return nil, 0, err
+			}
+			if resp == nil {
+				return nil, 0, fmt.Errorf("no nameservers to look up %s", host)
+			}
+			if resp.Rcode != dns.RcodeSuccess {
+				return nil, 0, fmt.Errorf("lookup of %s failed: %s", host, dns.RcodeToString[resp.Rcode])
+			}
+			for _, rr := range resp.Answer {
+				switch rr := rr.(type) {
+				case *dns.A:
+					ips = append(ips, rr.A)
+				case *dns.AAAA:
+					ips = append(ips, rr.AAAA)
+				}
+				if ttl == 0 || rr.Header().Ttl < ttl {
+					ttl = rr.Header().Ttl
+				}
+			}
+		}
+		return ips, time.Duration(ttl) * time.Second, nil
+	}
+}
+
+// resolutionCache holds the addresses of hosts until their TTL expires.
+type resolutionCache struct {
+	minTTL time.Duration
+	maxTTL time.Duration
+	now    func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]resolutionCacheEntry
+}
+
+// resolutionCacheEntry holds the cached addresses of a host.
+type resolutionCacheEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+// newResolutionCache creates a resolutionCache clamping TTLs between minTTL and maxTTL.
+func newResolutionCache(minTTL, maxTTL time.Duration) *resolutionCache {
+	return &resolutionCache{minTTL: minTTL, maxTTL: maxTTL, now: time.Now, entries: map[string]resolutionCacheEntry{}}
+}
+
+// get returns the cached addresses of the host unless they expired.
+func (c *resolutionCache) get(host string) ([]net.IP, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[host]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.ips, true
+}
+
+// set caches the addresses of the host for the TTL clamped between minTTL and maxTTL,
+// unless that is zero.
+func (c *resolutionCache) set(host string, ips []net.IP, ttl time.Duration) {
+	if ttl < c.minTTL {
+		ttl = c.minTTL
+	}
+	if ttl > c.maxTTL {
+		ttl = c.maxTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[host] = resolutionCacheEntry{ips: ips, expires: c.now().Add(ttl)}
+}
+
+// Flush removes all cached addresses.
+func (c *resolutionCache) Flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = map[string]resolutionCacheEntry{}
+}
+
+func (ms *flattenCNAMESource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that flattenCNAMESource is a Source
+var _ Source = &flattenCNAMESource{}
+
+func TestFlattenCNAMESource(t *testing.T) {
+	hosts := map[string][]net.IP{
+		"lb.example.com":    {net.ParseIP("1.1.1.2"), net.ParseIP("1.1.1.1"), net.ParseIP("2001:db8::1")},
+		"lb2.example.com.":  {net.ParseIP("1.1.1.1"), net.ParseIP("3.3.3.3")},
+		"empty.example.com": {},
+	}
+	lookup := func(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+		ips, ok := hosts[host]
+		if !ok {
+			return nil, 0, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return ips, 0, nil
+	}
+
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			title: "CNAME is replaced with the A and AAAA records of its target",
+			endpoints: []*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.com"}, RecordTTL: 300},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2"}, RecordTTL: 300},
+				{DNSName: "example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, RecordTTL: 300},
+			},
+		},
+		{
+			title: "addresses of multiple targets are merged",
+			endpoints: []*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.com", "lb2.example.com."}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2", "3.3.3.3"}},
+				{DNSName: "example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			title: "CNAME is kept when its target can't be resolved",
+			endpoints: []*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.com", "unknown.example.com"}},
+				{DNSName: "b.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"empty.example.com"}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.com", "unknown.example.com"}},
+				{DNSName: "b.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"empty.example.com"}},
+			},
+		},
+		{
+			title: "other records are passed through",
+			endpoints: []*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"4.4.4.4"}},
+				{DNSName: "example.org", RecordType: "TXT", Targets: endpoint.Targets{"lb.example.com"}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"4.4.4.4"}},
+				{DNSName: "example.org", RecordType: "TXT", Targets: endpoint.Targets{"lb.example.com"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].RecordType
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := &flattenCNAMESource{source: mockSource, lookup: lookup, cache: newResolutionCache(0, 0)}
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].RecordType, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+// newTestNameserver starts a nameserver on UDP and TCP answering lb.example.com with an A record
+// of the given TTL and truncating UDP answers for big.example.com. It counts the queries.
+func newTestNameserver(t *testing.T, ttl uint32) (string, *int32) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	pc, err := net.ListenPacket("udp", listener.Addr().String())
+	require.NoError(t, err)
+
+	var queries int32
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		atomic.AddInt32(&queries, 1)
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		q := req.Question[0]
+		_, udp := w.RemoteAddr().(*net.UDPAddr)
+		switch {
+		case q.Name == "big.example.com." && udp:
+			resp.Truncated = true
+		case q.Name != "lb.example.com." && q.Name != "big.example.com.":
+			resp.Rcode = dns.RcodeNameError
+		case q.Qtype == dns.TypeA:
+			resp.Answer = append(resp.Answer,
+				&dns.A{Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl}, A: net.ParseIP("1.1.1.1")},
+				&dns.A{Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl + 60}, A: net.ParseIP("1.1.1.2")},
+			)
+		case q.Qtype == dns.TypeAAAA:
+			resp.Answer = append(resp.Answer,
+				&dns.AAAA{Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: ttl + 300}, AAAA: net.ParseIP("2001:db8::1")},
+			)
+		}
+		_ = w.WriteMsg(resp)
+	})
+
+	udpServer := &dns.Server{PacketConn: pc, Handler: handler}
+	tcpServer := &dns.Server{Listener: listener, Handler: handler}
+	go func() { _ = udpServer.ActivateAndServe() }()
+	go func() { _ = tcpServer.ActivateAndServe() }()
+	t.Cleanup(func() {
+		_ = udpServer.Shutdown()
+		_ = tcpServer.Shutdown()
+	})
+
+	return listener.Addr().String(), &queries
+}
+
+func TestNewFlattenCNAMESourceValidatesTTLs(t *testing.T) {
+	for _, tc := range []struct {
+		minTTL time.Duration
+		maxTTL time.Duration
+	}{
+		{0, 0},
+		{time.Minute, 0},
+		{time.Minute, 30 * time.Second},
+		{-time.Second, time.Minute},
+	} {
+		_, err := NewFlattenCNAMESource(new(testutils.MockSource), "127.0.0.1:53", tc.minTTL, tc.maxTTL)
+		require.Error(t, err, "TTL range [%s, %s] must be rejected", tc.minTTL, tc.maxTTL)
+	}
+}
+
+func TestFlattenCNAMESourceFollowsAnswerTTL(t *testing.T) {
+	nameserver, queries := newTestNameserver(t, 120)
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.com"}},
+	}, nil)
+
+	source, err := NewFlattenCNAMESource(mockSource, nameserver, 10*time.Second, time.Hour)
+	require.NoError(t, err)
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := source.(*flattenCNAMESource).cache
+
+	for _, step := range []struct {
+		elapsed time.Duration
+		queries int32
+	}{
+		{0, 2},
+		{119 * time.Second, 2},
+		{120 * time.Second, 4},
+	} {
+		cache.now = func() time.Time { return now.Add(step.elapsed) }
+
+		endpoints, err := source.Endpoints(context.Background())
+		require.NoError(t, err)
+		validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+			{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2"}},
+			{DNSName: "example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+		})
+		assert.Equal(t, step.queries, atomic.LoadInt32(queries), "queries after %s", step.elapsed)
+	}
+}
+
+func TestNameserverLookup(t *testing.T) {
+	nameserver, _ := newTestNameserver(t, 120)
+
+	unreachable, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachableAddr := unreachable.LocalAddr().String()
+	require.NoError(t, unreachable.Close())
+
+	lookup := nameserverLookup([]string{unreachableAddr, nameserver})
+
+	ips, ttl, err := lookup(context.Background(), "lb.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []net.IP{net.ParseIP("1.1.1.1").To4(), net.ParseIP("1.1.1.2").To4(), net.ParseIP("2001:db8::1")}, ips)
+	assert.Equal(t, 2*time.Minute, ttl)
+
+	ips, _, err = lookup(context.Background(), "big.example.com")
+	require.NoError(t, err, "truncated answers must be retried over TCP")
+	assert.Len(t, ips, 3)
+
+	_, _, err = lookup(context.Background(), "unknown.example.com")
+	require.EqualError(t, err, "lookup of unknown.example.com failed: NXDOMAIN")
+}
+
+func TestFlattenCNAMESourceCache(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	lookups := 0
+	lookup := func(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+		lookups++
+		return []net.IP{net.ParseIP("1.1.1.1")}, time.Minute, nil
+	}
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "a.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.com"}},
+		{DNSName: "b.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.com"}},
+	}, nil)
+
+	cache := newResolutionCache(10*time.Second, 5*time.Minute)
+	cache.now = func() time.Time { return now }
+	source := &flattenCNAMESource{source: mockSource, lookup: lookup, cache: cache}
+
+	for _, step := range []struct {
+		elapsed time.Duration
+		flush   bool
+		lookups int
+	}{
+		{0, false, 1},
+		{30 * time.Second, false, 1},
+		{59 * time.Second, false, 1},
+		{time.Minute, false, 2},
+		{90 * time.Second, false, 2},
+		{90 * time.Second, true, 3},
+	} {
+		cache.now = func() time.Time { return now.Add(step.elapsed) }
+		if step.flush {
+			source.Flush()
+		}
+
+		endpoints, err := source.Endpoints(context.Background())
+		require.NoError(t, err)
+		validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+			{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+			{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		})
+		assert.Equal(t, step.lookups, lookups, "lookups after %s", step.elapsed)
+	}
+}
+
+func TestResolutionCacheClampsTTL(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	ips := []net.IP{net.ParseIP("1.1.1.1")}
+
+	for _, tc := range []struct {
+		ttl      time.Duration
+		expected time.Duration
+	}{
+		{0, 10 * time.Second},
+		{5 * time.Second, 10 * time.Second},
+		{time.Minute, time.Minute},
+		{time.Hour, 5 * time.Minute},
+	} {
+		t.Run(tc.ttl.String(), func(t *testing.T) {
+			cache := newResolutionCache(10*time.Second, 5*time.Minute)
+			cache.now = func() time.Time { return now }
+			cache.set("lb.example.com", ips, tc.ttl)
+
+			cache.now = func() time.Time { return now.Add(tc.expected - time.Nanosecond) }
+			_, ok := cache.get("lb.example.com")
+			assert.True(t, ok, "entry expected before the TTL")
+
+			cache.now = func() time.Time { return now.Add(tc.expected) }
+			_, ok = cache.get("lb.example.com")
+			assert.False(t, ok, "entry expected to expire after the TTL")
+		})
+	}
+}
+
+func TestResolutionCacheDisabled(t *testing.T) {
+	cache := newResolutionCache(0, 0)
+	cache.set("lb.example.com", []net.IP{net.ParseIP("1.1.1.1")}, time.Minute)
+
+	_, ok := cache.get("lb.example.com")
+	assert.False(t, ok, "entries must not be cached without a TTL")
+	assert.Empty(t, cache.entries)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// globalUniqueTargetSource is a Source that makes sure every IP address is a target of
+// at most one endpoint of its wrapped source.
+type globalUniqueTargetSource struct {
+	source Source
+}
+
+// NewGlobalUniqueTargetSource creates a new globalUniqueTargetSource wrapping the provided Source.
+func NewGlobalUniqueTargetSource(source Source) Source {
+	return &globalUniqueTargetSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and keeps each IP address target only on
+// the first endpoint using it, logging a warning for every later use. Duplicate targets within an
+// endpoint are merged first. Endpoints losing targets are copied and dropped if none remain.
+// Other targets and TXT records are left alone.
+func (ms *globalUniqueTargetSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := map[string]string{}
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType == endpoint.RecordTypeTXT {
+			result = append(result, ep)
+			continue
+		}
+
+		// repeated targets of the endpoint itself are removed without a warning
+		deduped := ep.Targets.Dedup()
+		own := map[string]bool{}
+		targets := endpoint.Targets{}
+		for _, t := range deduped {
+			ip := net.ParseIP(t)
+			if ip == nil {
+				targets = append(targets, t)
+				continue
+			}
+			key := ip.String()
+			if own[key] {
+				continue
+			}
+			own[key] = true
+			if owner, ok := owners[key]; ok {
+				log.Warnf("Removing target %s of endpoint %s already used by %s", t, ep, owner)
+				continue
+			}
+			owners[key] = ep.DNSName
+			targets = append(targets, t)
+		}
+
+		if len(targets) == len(ep.Targets) {
+			result = append(result, ep)
+			continue
+		}
+		if len(targets) == 0 {
+			log.Debugf("Dropping endpoint %s without remaining targets", ep)
+			continue
+		}
+		ep = ep.DeepCopy()
+		ep.Targets = targets
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *globalUniqueTargetSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
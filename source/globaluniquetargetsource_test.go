@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that globalUniqueTargetSource is a Source
+var _ Source = &globalUniqueTargetSource{}
+
+func TestGlobalUniqueTargetSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"unique targets are kept",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+		{
+			"shared target is kept by the first endpoint",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2", "3.3.3.3"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}},
+			},
+		},
+		{
+			"endpoints without remaining targets are dropped",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+				{DNSName: "bar.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:DB8:0::1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			"duplicate targets within an endpoint are merged",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.1"}},
+				{DNSName: "bar.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1", "2001:DB8:0::1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "bar.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			"hostnames and TXT records are left alone",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "www.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.org"}},
+				{DNSName: "api.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "www.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.org"}},
+				{DNSName: "api.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.org"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[1].Targets.String()
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewGlobalUniqueTargetSource(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[1].Targets.String(), "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// healthCheckSource is a Source that applies the same health check property to all weighted
+// records of a group of its wrapped source.
+type healthCheckSource struct {
+	source   Source
+	property string
+}
+
+// NewHealthCheckSource creates a new healthCheckSource wrapping the provided Source. The health
+// check is read from the provider specific property with the given name, e.g. "aws/health-check-id".
+func NewHealthCheckSource(source Source, property string) Source {
+	return &healthCheckSource{source: source, property: property}
+}
+
+// Endpoints collects endpoints from its wrapped source and, within each group of records sharing
+// DNS name and record type but differing in their set identifier, sets the health check property
+// of the first member defining one on all other members. Members with a different health check
+// are overridden with a warning, groups without any health check are left untouched.
+func (ms *healthCheckSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	healthChecks := map[endpointKey]string{}
+	for _, ep := range endpoints {
+		if ep.SetIdentifier == "" {
+			continue
+		}
+		key := endpointKey{dnsName: ep.DNSName, recordType: ep.RecordType}
+		if _, ok := healthChecks[key]; ok {
+			continue
+		}
+		if prop, ok := ep.GetProviderSpecificProperty(ms.property); ok {
+			healthChecks[key] = prop.Value
+		}
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		healthCheck, ok := healthChecks[endpointKey{dnsName: ep.DNSName, recordType: ep.RecordType}]
+		if !ok || ep.SetIdentifier == "" {
+			result = append(result, ep)
+			continue
+		}
+		result = append(result, ms.withHealthCheck(ep, healthCheck))
+	}
+
+	return result, nil
+}
+
+// withHealthCheck returns the endpoint with the given health check, copying it if needed.
+func (ms *healthCheckSource) withHealthCheck(ep *endpoint.Endpoint, healthCheck string) *endpoint.Endpoint {
+	prop, ok := ep.GetProviderSpecificProperty(ms.property)
+	if ok && prop.Value == healthCheck {
+		return ep
+	}
+
+	ep = ep.DeepCopy()
+	if !ok {
+		return ep.WithProviderSpecific(ms.property, healthCheck)
+	}
+
+	log.Warnf("Overriding health check %s of %s with %s of its group", prop.Value, ep, healthCheck)
+	for i := range ep.ProviderSpecific {
+		if ep.ProviderSpecific[i].Name == ms.property {
+			ep.ProviderSpecific[i].Value = healthCheck
+		}
+	}
+	return ep
+}
+
+func (ms *healthCheckSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
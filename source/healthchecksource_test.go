@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that healthCheckSource is a Source
+var _ Source = &healthCheckSource{}
+
+func TestHealthCheckSource(t *testing.T) {
+	const property = "aws/health-check-id"
+
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"members without health check get the first one of the group",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"2.2.2.2"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-b"},
+				}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "c", Targets: endpoint.Targets{"3.3.3.3"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: "aws/weight", Value: "10"},
+				}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-b"},
+				}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"2.2.2.2"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-b"},
+				}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "c", Targets: endpoint.Targets{"3.3.3.3"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: "aws/weight", Value: "10"},
+					{Name: property, Value: "hc-b"},
+				}},
+			},
+		},
+		{
+			"conflicting health checks are overridden by the first one",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-a"},
+				}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"2.2.2.2"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-b"},
+				}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-a"},
+				}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"2.2.2.2"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-a"},
+				}},
+			},
+		},
+		{
+			"groups without health check and unweighted records are left alone",
+			[]*endpoint.Endpoint{
+				{DNSName: "bar.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "bar.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"2.2.2.2"}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"3.3.3.3"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-a"},
+				}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"4.4.4.4"}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", SetIdentifier: "b", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "bar.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "bar.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"2.2.2.2"}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"3.3.3.3"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: property, Value: "hc-a"},
+				}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"4.4.4.4"}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", SetIdentifier: "b", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := len(tc.endpoints[0].ProviderSpecific)
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewHealthCheckSource(mockSource, property)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Len(t, tc.endpoints[0].ProviderSpecific, original, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// hysteresisSource is a Source that delays target changes of the address records of its
+// wrapped source until they have been seen in a number of consecutive syncs.
+type hysteresisSource struct {
+	source Source
+	syncs  int
+
+	mutex  sync.Mutex
+	states map[endpointKey]*hysteresisState
+}
+
+// hysteresisState tracks the published and the pending targets of a record.
+type hysteresisState struct {
+	published endpoint.Targets
+	pending   endpoint.Targets
+	seen      int
+}
+
+// NewHysteresisSource creates a new hysteresisSource wrapping the provided Source.
+// A target change is published once it persisted for the given number of syncs.
+func NewHysteresisSource(source Source, syncs int) Source {
+	return &hysteresisSource{source: source, syncs: syncs, states: map[endpointKey]*hysteresisState{}}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns A and AAAA records with the
+// targets published last until a change of their targets persisted long enough. New records
+// are published right away and records that disappear are forgotten. Other records are passed through.
+func (ms *hysteresisSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	states := map[endpointKey]*hysteresisState{}
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA {
+			result = append(result, ep)
+			continue
+		}
+
+		key := endpointKey{dnsName: ep.DNSName, recordType: ep.RecordType, setIdentifier: ep.SetIdentifier}
+		state, ok := ms.states[key]
+		if !ok {
+			state = &hysteresisState{published: append(endpoint.Targets{}, ep.Targets...)}
+		}
+		states[key] = state
+
+		switch {
+		case sameTargets(ep.Targets, state.published):
+			state.pending, state.seen = nil, 0
+		case state.seen > 0 && sameTargets(ep.Targets, state.pending):
+			state.seen++
+		default:
+			state.pending, state.seen = append(endpoint.Targets{}, ep.Targets...), 1
+		}
+
+		if state.seen >= ms.syncs {
+			state.published = append(endpoint.Targets{}, ep.Targets...)
+			state.pending, state.seen = nil, 0
+		}
+
+		if state.seen > 0 {
+			log.Debugf("Holding back target change of %s for %d more syncs", ep, ms.syncs-state.seen)
+			ep = ep.DeepCopy()
+			ep.Targets = append(endpoint.Targets{}, state.published...)
+		}
+		result = append(result, ep)
+	}
+	ms.states = states
+
+	return result, nil
+}
+
+// sameTargets returns true if both lists hold the same targets regardless of their order.
+func sameTargets(a, b endpoint.Targets) bool {
+	return targetsKey(a) == targetsKey(b)
+}
+
+// targetsKey returns the sorted targets joined into a string.
+func targetsKey(targets endpoint.Targets) string {
+	sorted := append([]string{}, targets...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ";")
+}
+
+func (ms *hysteresisSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
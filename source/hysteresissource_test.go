@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that hysteresisSource is a Source
+var _ Source = &hysteresisSource{}
+
+func TestHysteresisSource(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		syncs    int
+		targets  []endpoint.Targets
+		expected []endpoint.Targets
+	}{
+		{
+			"transient change is not published",
+			3,
+			[]endpoint.Targets{{"1.1.1.1"}, {"2.2.2.2"}, {"2.2.2.2"}, {"1.1.1.1"}, {"1.1.1.1"}},
+			[]endpoint.Targets{{"1.1.1.1"}, {"1.1.1.1"}, {"1.1.1.1"}, {"1.1.1.1"}, {"1.1.1.1"}},
+		},
+		{
+			"persistent change is published after the configured syncs",
+			3,
+			[]endpoint.Targets{{"1.1.1.1"}, {"2.2.2.2"}, {"2.2.2.2"}, {"2.2.2.2"}, {"2.2.2.2"}},
+			[]endpoint.Targets{{"1.1.1.1"}, {"1.1.1.1"}, {"1.1.1.1"}, {"2.2.2.2"}, {"2.2.2.2"}},
+		},
+		{
+			"alternating changes restart the count",
+			2,
+			[]endpoint.Targets{{"1.1.1.1"}, {"2.2.2.2"}, {"3.3.3.3"}, {"3.3.3.3"}},
+			[]endpoint.Targets{{"1.1.1.1"}, {"1.1.1.1"}, {"1.1.1.1"}, {"3.3.3.3"}},
+		},
+		{
+			"reordered targets are not a change",
+			2,
+			[]endpoint.Targets{{"1.1.1.1", "2.2.2.2"}, {"2.2.2.2", "1.1.1.1"}},
+			[]endpoint.Targets{{"1.1.1.1", "2.2.2.2"}, {"2.2.2.2", "1.1.1.1"}},
+		},
+		{
+			"a single sync publishes changes right away",
+			1,
+			[]endpoint.Targets{{"1.1.1.1"}, {"2.2.2.2"}},
+			[]endpoint.Targets{{"1.1.1.1"}, {"2.2.2.2"}},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			for _, targets := range tc.targets {
+				mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+					{DNSName: "foo.example.org", RecordType: "A", Targets: targets},
+					{DNSName: "foo.example.org", RecordType: "TXT", Targets: targets},
+				}, nil).Once()
+			}
+
+			source := NewHysteresisSource(mockSource, tc.syncs)
+
+			for i, targets := range tc.expected {
+				endpoints, err := source.Endpoints(context.Background())
+				require.NoError(t, err)
+
+				validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+					{DNSName: "foo.example.org", RecordType: "A", Targets: targets},
+					{DNSName: "foo.example.org", RecordType: "TXT", Targets: tc.targets[i]},
+				})
+			}
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
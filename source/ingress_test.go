@@ -279,6 +279,29 @@ func testEndpointsFromIngressHostnameSourceAnnotation(t *testing.T) {
 				},
 			},
 		},
+		{
+			title: "record data annotations on an annotation host",
+			ingress: fakeIngress{
+				dnsnames:    []string{"foo.bar"},
+				annotations: map[string]string{hostnameAnnotationKey: "foo.baz", hinfoAnnotationKey: `"Intel, x86" "Linux"`},
+				hostnames:   []string{"lb.com"},
+			},
+			expected: []*endpoint.Endpoint{
+				{
+					DNSName: "foo.bar",
+					Targets: endpoint.Targets{"lb.com"},
+				},
+				{
+					DNSName: "foo.baz",
+					Targets: endpoint.Targets{"lb.com"},
+				},
+				{
+					DNSName:    "foo.baz",
+					RecordType: endpoint.RecordTypeHINFO,
+					Targets:    endpoint.Targets{`"Intel, x86" "Linux"`},
+				},
+			},
+		},
 		{
 			title: "No ingress-hostname-source annotation, one rule.host",
 			ingress: fakeIngress{
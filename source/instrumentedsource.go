@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var (
+	instrumentedCallsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "endpoints_calls_total",
+			Help:      "Number of calls to Endpoints of an instrumented source.",
+		},
+		[]string{"name"},
+	)
+	instrumentedEndpointsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "endpoints_returned_total",
+			Help:      "Number of endpoints returned by an instrumented source.",
+		},
+		[]string{"name"},
+	)
+	instrumentedErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "endpoints_errors_total",
+			Help:      "Number of errors returned by Endpoints of an instrumented source.",
+		},
+		[]string{"name"},
+	)
+	instrumentedDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "endpoints_duration_seconds",
+			Help:      "Duration of calls to Endpoints of an instrumented source.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"name"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(instrumentedCallsTotal)
+	prometheus.MustRegister(instrumentedEndpointsTotal)
+	prometheus.MustRegister(instrumentedErrorsTotal)
+	prometheus.MustRegister(instrumentedDuration)
+}
+
+// instrumentedSource is a Source that records Prometheus metrics about the calls to its wrapped source.
+type instrumentedSource struct {
+	name   string
+	source Source
+}
+
+// NewInstrumentedSource creates a new instrumentedSource wrapping the provided Source.
+// The metrics are labeled with the given name.
+func NewInstrumentedSource(name string, source Source) Source {
+	return &instrumentedSource{name: name, source: source}
+}
+
+// Endpoints returns the endpoints of its wrapped source and counts the call, the returned
+// endpoints and errors, and observes the duration of the call.
+func (ms *instrumentedSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	start := time.Now()
+	endpoints, err := ms.source.Endpoints(ctx)
+	instrumentedDuration.WithLabelValues(ms.name).Observe(time.Since(start).Seconds())
+	instrumentedCallsTotal.WithLabelValues(ms.name).Inc()
+
+	if err != nil {
+		instrumentedErrorsTotal.WithLabelValues(ms.name).Inc()
+		return nil, err
+	}
+
+	instrumentedEndpointsTotal.WithLabelValues(ms.name).Add(float64(len(endpoints)))
+	return endpoints, nil
+}
+
+func (ms *instrumentedSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
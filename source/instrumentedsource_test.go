@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Validates that instrumentedSource is a Source
+var _ Source = &instrumentedSource{}
+
+func TestInstrumentedSource(t *testing.T) {
+	t.Run("Endpoints", testInstrumentedSourceEndpoints)
+	t.Run("Error", testInstrumentedSourceError)
+}
+
+// testInstrumentedSourceEndpoints tests that calls and returned endpoints are counted.
+func testInstrumentedSourceEndpoints(t *testing.T) {
+	inner := &countingSource{endpoints: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "5.6.7.8"),
+		endpoint.NewEndpoint("baz.example.org", endpoint.RecordTypeA, "9.9.9.9"),
+	}}
+	source := NewInstrumentedSource("test-endpoints", inner)
+
+	for i := 0; i < 2; i++ {
+		endpoints, err := source.Endpoints(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, endpoints, 3)
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(instrumentedCallsTotal.WithLabelValues("test-endpoints")))
+	assert.Equal(t, 6.0, testutil.ToFloat64(instrumentedEndpointsTotal.WithLabelValues("test-endpoints")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(instrumentedErrorsTotal.WithLabelValues("test-endpoints")))
+
+	metric := &dto.Metric{}
+	require.NoError(t, instrumentedDuration.WithLabelValues("test-endpoints").(prometheus.Histogram).Write(metric))
+	assert.Equal(t, uint64(2), metric.GetHistogram().GetSampleCount())
+}
+
+// testInstrumentedSourceError tests that errors are counted.
+func testInstrumentedSourceError(t *testing.T) {
+	inner := &countingSource{err: errors.New("failed")}
+	source := NewInstrumentedSource("test-error", inner)
+
+	_, err := source.Endpoints(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(instrumentedCallsTotal.WithLabelValues("test-error")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(instrumentedEndpointsTotal.WithLabelValues("test-error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(instrumentedErrorsTotal.WithLabelValues("test-error")))
+}
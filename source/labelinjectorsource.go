@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// labelInjectorSource is a Source that adds a fixed set of labels to the endpoints of its wrapped
+// source, e.g. to tag the records of one cluster when several clusters share a zone.
+type labelInjectorSource struct {
+	source Source
+	labels map[string]string
+}
+
+// NewLabelInjectorSource creates a new labelInjectorSource wrapping the provided Source.
+func NewLabelInjectorSource(source Source, labels map[string]string) Source {
+	return &labelInjectorSource{source: source, labels: labels}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of them with the
+// configured labels merged in. Labels the endpoints already set are not overwritten.
+func (ms *labelInjectorSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ms.labels) == 0 {
+		return endpoints, nil
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		ep = ep.DeepCopy()
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		for key, value := range ms.labels {
+			if _, ok := ep.Labels[key]; !ok {
+				ep.Labels[key] = value
+			}
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *labelInjectorSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that labelInjectorSource is a Source
+var _ Source = &labelInjectorSource{}
+
+func TestLabelInjectorSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"labels are injected into nil labels",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					"cluster": "prod",
+					"region":  "eu",
+				}},
+			},
+		},
+		{
+			"labels are merged into existing labels",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.ResourceLabelKey: "node/node1",
+				}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.ResourceLabelKey: "node/node1",
+					"cluster":                 "prod",
+					"region":                  "eu",
+				}},
+			},
+		},
+		{
+			"existing labels are not overwritten",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					"cluster": "staging",
+				}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					"cluster": "staging",
+					"region":  "eu",
+				}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := len(tc.endpoints[0].Labels)
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewLabelInjectorSource(mockSource, map[string]string{"cluster": "prod", "region": "eu"})
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Len(t, tc.endpoints[0].Labels, original, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
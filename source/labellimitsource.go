@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// labelLimitSource is a Source that bounds the number of labels of the endpoints of its
+// wrapped source, which the registry serializes into TXT records.
+type labelLimitSource struct {
+	source    Source
+	maxLabels int
+}
+
+// NewLabelLimitSource creates a new labelLimitSource wrapping the provided Source.
+func NewLabelLimitSource(source Source, maxLabels int) Source {
+	return &labelLimitSource{source: source, maxLabels: maxLabels}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of those with more
+// than maxLabels labels, keeping the labels managed by the registries first and the others
+// in the order of their keys. The labels managed by the registries are never dropped.
+func (ms *labelLimitSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if len(ep.Labels) > ms.maxLabels {
+			ep = ep.DeepCopy()
+			ep.Labels = ms.limit(ep)
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// limit returns the labels of the endpoint to keep.
+func (ms *labelLimitSource) limit(ep *endpoint.Endpoint) endpoint.Labels {
+	keys := make([]string, 0, len(ep.Labels))
+	for key := range ep.Labels {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if reservedLabelKeys[keys[i]] != reservedLabelKeys[keys[j]] {
+			return reservedLabelKeys[keys[i]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	limited := endpoint.NewLabels()
+	for i, key := range keys {
+		if i >= ms.maxLabels && !reservedLabelKeys[key] {
+			log.Debugf("Dropping label %s of endpoint %s exceeding the limit of %d labels", key, ep, ms.maxLabels)
+			continue
+		}
+		limited[key] = ep.Labels[key]
+	}
+	return limited
+}
+
+func (ms *labelLimitSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
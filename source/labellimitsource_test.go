@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that labelLimitSource is a Source
+var _ Source = &labelLimitSource{}
+
+func TestLabelLimitSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		maxLabels int
+		labels    endpoint.Labels
+		expected  endpoint.Labels
+	}{
+		{
+			"labels within the limit are kept",
+			3,
+			endpoint.Labels{endpoint.OwnerLabelKey: "default", "a": "1", "b": "2"},
+			endpoint.Labels{endpoint.OwnerLabelKey: "default", "a": "1", "b": "2"},
+		},
+		{
+			"labels beyond the limit are dropped by key order",
+			3,
+			endpoint.Labels{"d": "4", "b": "2", "c": "3", "a": "1"},
+			endpoint.Labels{"a": "1", "b": "2", "c": "3"},
+		},
+		{
+			"registry labels are kept first",
+			3,
+			endpoint.Labels{"a": "1", "b": "2", endpoint.ResourceLabelKey: "node/node1", endpoint.OwnerLabelKey: "default"},
+			endpoint.Labels{"a": "1", endpoint.ResourceLabelKey: "node/node1", endpoint.OwnerLabelKey: "default"},
+		},
+		{
+			"registry labels are never dropped",
+			1,
+			endpoint.Labels{"a": "1", endpoint.ResourceLabelKey: "node/node1", endpoint.OwnerLabelKey: "default"},
+			endpoint.Labels{endpoint.ResourceLabelKey: "node/node1", endpoint.OwnerLabelKey: "default"},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			endpoints := []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: tc.labels},
+			}
+			original := len(tc.labels)
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(endpoints, nil)
+
+			source := NewLabelLimitSource(mockSource, tc.maxLabels)
+
+			for i := 0; i < 2; i++ {
+				result, err := source.Endpoints(context.Background())
+				require.NoError(t, err)
+				validateEndpoints(t, result, []*endpoint.Endpoint{
+					{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: tc.expected},
+				})
+			}
+			require.Len(t, tc.labels, original, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
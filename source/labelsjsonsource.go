@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// labelsJSON is the content of a TXT record published by labelsJSONSource.
+type labelsJSON struct {
+	RecordType    string            `json:"recordType"`
+	SetIdentifier string            `json:"setIdentifier,omitempty"`
+	Labels        map[string]string `json:"labels"`
+}
+
+// labelsJSONSource is a Source that publishes the labels of the endpoints of its wrapped source
+// as JSON encoded TXT records, so that tools can read them without parsing the registry format.
+type labelsJSONSource struct {
+	source Source
+	prefix string
+}
+
+// NewLabelsJSONSource creates a new labelsJSONSource wrapping the provided Source.
+// The TXT records are named after the labeled endpoints with the given prefix prepended.
+// The prefix must not be empty, as the TXT records would otherwise collide with the records
+// of the TXT registry and with CNAME records.
+func NewLabelsJSONSource(source Source, prefix string) (Source, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("labels JSON records require a prefix")
+	}
+	return &labelsJSONSource{source: source, prefix: prefix}, nil
+}
+
+// Endpoints collects endpoints from its wrapped source and appends a TXT record for each
+// DNS name with labeled endpoints. It holds one JSON object per labeled endpoint.
+func (ms *labelsJSONSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, len(endpoints), len(endpoints)+1)
+	copy(result, endpoints)
+	siblings := map[string]*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if len(ep.Labels) == 0 {
+			continue
+		}
+
+		data, err := json.Marshal(labelsJSON{
+			RecordType:    ep.RecordType,
+			SetIdentifier: ep.SetIdentifier,
+			Labels:        ep.Labels,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		name := ms.prefix + ep.DNSName
+		if sibling, ok := siblings[name]; ok {
+			sibling.Targets = append(sibling.Targets, string(data))
+			continue
+		}
+		sibling := endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeTXT, ep.RecordTTL, string(data))
+		if sibling == nil {
+			continue
+		}
+		siblings[name] = sibling
+		result = append(result, sibling)
+	}
+
+	return result, nil
+}
+
+func (ms *labelsJSONSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
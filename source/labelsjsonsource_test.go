@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that labelsJSONSource is a Source
+var _ Source = &labelsJSONSource{}
+
+func TestLabelsJSONSource(t *testing.T) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 300, Labels: endpoint.Labels{
+			endpoint.OwnerLabelKey:    "default",
+			endpoint.ResourceLabelKey: "node/node1",
+		}},
+		{DNSName: "foo.example.org", RecordType: "AAAA", SetIdentifier: "v6", Targets: endpoint.Targets{"2001:db8::1"}, Labels: endpoint.Labels{
+			endpoint.OwnerLabelKey: "default",
+		}},
+		{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+	}, nil)
+
+	source, err := NewLabelsJSONSource(mockSource, "meta.")
+	require.NoError(t, err)
+
+	endpoints, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	require.Len(t, endpoints, 4)
+
+	sibling := endpoints[3]
+	assert.Equal(t, "meta.foo.example.org", sibling.DNSName)
+	assert.Equal(t, endpoint.RecordTypeTXT, sibling.RecordType)
+	assert.Equal(t, endpoint.TTL(300), sibling.RecordTTL)
+	require.Len(t, sibling.Targets, 2)
+
+	var first, second labelsJSON
+	require.NoError(t, json.Unmarshal([]byte(sibling.Targets[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(sibling.Targets[1]), &second))
+	assert.Equal(t, labelsJSON{
+		RecordType: "A",
+		Labels:     map[string]string{endpoint.OwnerLabelKey: "default", endpoint.ResourceLabelKey: "node/node1"},
+	}, first)
+	assert.Equal(t, labelsJSON{
+		RecordType:    "AAAA",
+		SetIdentifier: "v6",
+		Labels:        map[string]string{endpoint.OwnerLabelKey: "default"},
+	}, second)
+	assert.JSONEq(t, `{"recordType":"A","labels":{"owner":"default","resource":"node/node1"}}`, sibling.Targets[0])
+
+	mockSource.AssertExpectations(t)
+}
+
+func TestLabelsJSONSourceRequiresPrefix(t *testing.T) {
+	_, err := NewLabelsJSONSource(new(testutils.MockSource), "")
+	require.EqualError(t, err, "labels JSON records require a prefix")
+}
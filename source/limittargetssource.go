@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// limitTargetsSource is a Source that truncates the targets of the endpoints of its wrapped source.
+type limitTargetsSource struct {
+	source  Source
+	max     int
+	shuffle bool
+}
+
+// NewLimitTargetsSource creates a new limitTargetsSource wrapping the provided Source
+// which keeps the first max targets of each endpoint. A max of zero or less means no limit.
+func NewLimitTargetsSource(source Source, max int) Source {
+	if max <= 0 {
+		return source
+	}
+	return &limitTargetsSource{source: source, max: max}
+}
+
+// NewShuffledLimitTargetsSource creates a new limitTargetsSource wrapping the provided Source
+// which keeps max targets of each endpoint picked by a shuffle seeded with the DNS name, so that
+// different records keep different targets while each record keeps the same ones on every sync.
+// A max of zero or less means no limit.
+func NewShuffledLimitTargetsSource(source Source, max int) Source {
+	if max <= 0 {
+		return source
+	}
+	return &limitTargetsSource{source: source, max: max, shuffle: true}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of the endpoints
+// with more than max targets truncated to max targets.
+func (ms *limitTargetsSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if len(ep.Targets) <= ms.max {
+			result = append(result, ep)
+			continue
+		}
+
+		log.Infof("Dropping %d of %d targets of endpoint %s exceeding the limit of %d", len(ep.Targets)-ms.max, len(ep.Targets), ep, ms.max)
+		targets := append(endpoint.Targets{}, ep.Targets...)
+		if ms.shuffle {
+			shuffleTargets(ep.DNSName, targets)
+		}
+		ep = ep.DeepCopy()
+		ep.Targets = targets[:ms.max]
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// shuffleTargets sorts the targets and shuffles them with the hash of the DNS name as seed,
+// so that the result doesn't depend on the order of the targets.
+func shuffleTargets(dnsName string, targets endpoint.Targets) {
+	sort.Strings(targets)
+
+	h := fnv.New64a()
+	h.Write([]byte(dnsName))
+	r := rand.New(rand.NewSource(int64(h.Sum64())))
+	r.Shuffle(len(targets), func(i, j int) {
+		targets[i], targets[j] = targets[j], targets[i]
+	})
+}
+
+func (ms *limitTargetsSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
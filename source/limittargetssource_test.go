@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that limitTargetsSource is a Source
+var _ Source = &limitTargetsSource{}
+
+func TestLimitTargetsSource(t *testing.T) {
+	t.Run("FirstTargets", testLimitTargetsSourceFirstTargets)
+	t.Run("Shuffle", testLimitTargetsSourceShuffle)
+	t.Run("NoLimit", testLimitTargetsSourceNoLimit)
+}
+
+// testLimitTargetsSourceFirstTargets tests that the first targets of over-limit endpoints are kept.
+func testLimitTargetsSourceFirstTargets(t *testing.T) {
+	underLimit := &endpoint.Endpoint{DNSName: "bar.example.org", Targets: endpoint.Targets{"5.5.5.5", "6.6.6.6"}}
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "foo.example.org", Targets: endpoint.Targets{"4.4.4.4", "1.1.1.1", "3.3.3.3", "2.2.2.2"}},
+		underLimit,
+	}, nil)
+
+	endpoints, err := NewLimitTargetsSource(mockSource, 2).Endpoints(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, endpoint.Targets{"4.4.4.4", "1.1.1.1"}, endpoints[0].Targets)
+	assert.Same(t, underLimit, endpoints[1], "endpoints within the limit must be passed through")
+
+	mockSource.AssertExpectations(t)
+}
+
+// testLimitTargetsSourceShuffle tests that shuffled targets are deterministic for a DNS name.
+func testLimitTargetsSourceShuffle(t *testing.T) {
+	targets := endpoint.Targets{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4", "5.5.5.5", "6.6.6.6", "7.7.7.7", "8.8.8.8"}
+	reversed := endpoint.Targets{}
+	for i := len(targets) - 1; i >= 0; i-- {
+		reversed = append(reversed, targets[i])
+	}
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "foo.example.org", Targets: targets},
+	}, nil).Once()
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "foo.example.org", Targets: reversed},
+	}, nil).Once()
+
+	source := NewShuffledLimitTargetsSource(mockSource, 3)
+
+	first, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	second, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, first[0].Targets, 3)
+	assert.Equal(t, first[0].Targets, second[0].Targets, "the same targets must be kept regardless of their order")
+	assert.Equal(t, "1.1.1.1", targets[0], "wrapped endpoints must not be modified")
+
+	mockSource.AssertExpectations(t)
+}
+
+// testLimitTargetsSourceNoLimit tests that a limit of zero or less keeps all targets.
+func testLimitTargetsSourceNoLimit(t *testing.T) {
+	for _, max := range []int{0, -1} {
+		for _, newSource := range []func(Source, int) Source{NewLimitTargetsSource, NewShuffledLimitTargetsSource} {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}},
+			}, nil)
+
+			endpoints, err := newSource(mockSource, max).Endpoints(context.Background())
+			require.NoError(t, err)
+
+			require.Len(t, endpoints, 1)
+			assert.Equal(t, endpoint.Targets{"1.1.1.1", "2.2.2.2"}, endpoints[0].Targets, "max %d must not limit targets", max)
+
+			mockSource.AssertExpectations(t)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// managedZoneFilterSource is a Source that removes endpoints outside of the managed zones from its wrapped source.
+type managedZoneFilterSource struct {
+	source Source
+	zones  endpoint.DomainFilter
+}
+
+// NewManagedZoneFilterSource creates a new managedZoneFilterSource wrapping the provided Source.
+// Like the domain filter, an empty list of zones keeps all endpoints.
+func NewManagedZoneFilterSource(source Source, zones []string) Source {
+	return &managedZoneFilterSource{source: source, zones: endpoint.NewDomainFilter(zones)}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns only those whose
+// DNS name is within one of the managed zones.
+func (ms *managedZoneFilterSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if !ms.zones.Match(ep.DNSName) {
+			log.Debugf("Dropping endpoint %s outside of the managed zones", ep)
+			continue
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *managedZoneFilterSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that managedZoneFilterSource is a Source
+var _ Source = &managedZoneFilterSource{}
+
+func TestManagedZoneFilterSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		zones     []string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"in-zone names are kept",
+			[]string{"example.org", "example.com"},
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.com.", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.com.", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			"out-of-zone names are dropped",
+			[]string{"example.org"},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.com", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "fooexample.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			"no zones keep all names",
+			nil,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.com", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.com", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewManagedZoneFilterSource(mockSource, tc.zones)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
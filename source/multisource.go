@@ -18,24 +18,46 @@ package source
 
 import (
 	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
+const (
+	// MultiSourceErrorPolicyAll makes multiSource fail if any nested Source fails.
+	MultiSourceErrorPolicyAll = "all"
+	// MultiSourceErrorPolicyBestEffort makes multiSource log failures of nested Sources and
+	// return the endpoints of the others.
+	MultiSourceErrorPolicyBestEffort = "best-effort"
+)
+
 // multiSource is a Source that merges the endpoints of its nested Sources.
 type multiSource struct {
 	children       []Source
 	defaultTargets []string
+	errorPolicy    string
 }
 
-// Endpoints collects endpoints of all nested Sources and returns them in a single slice.
+// Endpoints collects endpoints of all nested Sources and returns them in a single slice,
+// in the order of the nested Sources. With the best-effort error policy, failing nested
+// Sources are logged and skipped, and an error is only returned if all of them fail.
 func (ms *multiSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	result := []*endpoint.Endpoint{}
 
+	var lastErr error
+	failed := 0
 	for _, s := range ms.children {
 		endpoints, err := s.Endpoints(ctx)
 		if err != nil {
-			return nil, err
+			if ms.errorPolicy != MultiSourceErrorPolicyBestEffort {
+				return nil, err
+			}
+			log.Errorf("Skipping endpoints of failing source: %v", err)
+			lastErr = err
+			failed++
+			continue
 		}
 		if len(ms.defaultTargets) > 0 {
 			for i := range endpoints {
@@ -44,6 +66,9 @@ func (ms *multiSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, err
 		}
 		result = append(result, endpoints...)
 	}
+	if failed > 0 && failed == len(ms.children) {
+		return nil, lastErr
+	}
 
 	return result, nil
 }
@@ -56,5 +81,17 @@ func (ms *multiSource) AddEventHandler(ctx context.Context, handler func()) {
 
 // NewMultiSource creates a new multiSource.
 func NewMultiSource(children []Source, defaultTargets []string) Source {
-	return &multiSource{children: children, defaultTargets: defaultTargets}
+	return &multiSource{children: children, defaultTargets: defaultTargets, errorPolicy: MultiSourceErrorPolicyAll}
+}
+
+// NewMultiSourceWithErrorPolicy creates a new multiSource handling failures of its nested
+// Sources according to the error policy, either MultiSourceErrorPolicyAll or
+// MultiSourceErrorPolicyBestEffort.
+func NewMultiSourceWithErrorPolicy(children []Source, defaultTargets []string, errorPolicy string) (Source, error) {
+	switch errorPolicy {
+	case MultiSourceErrorPolicyAll, MultiSourceErrorPolicyBestEffort:
+	default:
+		return nil, fmt.Errorf("unknown multi source error policy %q", errorPolicy)
+	}
+	return &multiSource{children: children, defaultTargets: defaultTargets, errorPolicy: errorPolicy}, nil
 }
@@ -35,6 +35,10 @@ func TestMultiSource(t *testing.T) {
 	t.Run("Endpoints", testMultiSourceEndpoints)
 	t.Run("EndpointsWithError", testMultiSourceEndpointsWithError)
 	t.Run("EndpointsDefaultTargets", testMultiSourceEndpointsDefaultTargets)
+	t.Run("ErrorPolicy", testMultiSourceErrorPolicy)
+	t.Run("ErrorPolicyDefaultTargets", testMultiSourceErrorPolicyDefaultTargets)
+	t.Run("UnknownErrorPolicy", testMultiSourceUnknownErrorPolicy)
+	t.Run("AddEventHandler", testMultiSourceAddEventHandler)
 }
 
 // testMultiSourceImplementsSource tests that multiSource is a valid Source.
@@ -159,3 +163,114 @@ func testMultiSourceEndpointsDefaultTargets(t *testing.T) {
 	// Validate that the nested sources were called.
 	src.AssertExpectations(t)
 }
+
+// testMultiSourceErrorPolicy tests that failing children are handled according to the error policy.
+func testMultiSourceErrorPolicy(t *testing.T) {
+	foo := &endpoint.Endpoint{DNSName: "foo", Targets: endpoint.Targets{"8.8.8.8"}}
+	bar := &endpoint.Endpoint{DNSName: "bar", Targets: endpoint.Targets{"8.8.4.4"}}
+
+	for _, tc := range []struct {
+		title       string
+		policy      string
+		errs        []error
+		expected    []*endpoint.Endpoint
+		expectError string
+	}{
+		{
+			title:    "all policy merges endpoints of all children",
+			policy:   MultiSourceErrorPolicyAll,
+			errs:     []error{nil, nil},
+			expected: []*endpoint.Endpoint{foo, bar},
+		},
+		{
+			title:       "all policy fails if a child fails",
+			policy:      MultiSourceErrorPolicyAll,
+			errs:        []error{nil, errors.New("bar failed")},
+			expectError: "bar failed",
+		},
+		{
+			title:    "best-effort policy merges endpoints of all children",
+			policy:   MultiSourceErrorPolicyBestEffort,
+			errs:     []error{nil, nil},
+			expected: []*endpoint.Endpoint{foo, bar},
+		},
+		{
+			title:    "best-effort policy skips failing children",
+			policy:   MultiSourceErrorPolicyBestEffort,
+			errs:     []error{errors.New("foo failed"), nil},
+			expected: []*endpoint.Endpoint{bar},
+		},
+		{
+			title:       "best-effort policy fails if all children fail",
+			policy:      MultiSourceErrorPolicyBestEffort,
+			errs:        []error{errors.New("foo failed"), errors.New("bar failed")},
+			expectError: "bar failed",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			sources := make([]Source, 0, len(tc.errs))
+			for i, ep := range []*endpoint.Endpoint{foo, bar} {
+				src := new(testutils.MockSource)
+				src.On("Endpoints").Return([]*endpoint.Endpoint{ep}, tc.errs[i])
+				sources = append(sources, src)
+			}
+
+			source, err := NewMultiSourceWithErrorPolicy(sources, nil, tc.policy)
+			require.NoError(t, err)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if tc.expectError != "" {
+				require.EqualError(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			validateEndpoints(t, endpoints, tc.expected)
+
+			for _, src := range sources {
+				src.(*testutils.MockSource).AssertExpectations(t)
+			}
+		})
+	}
+}
+
+// testMultiSourceAddEventHandler tests that handlers are registered with all children.
+func testMultiSourceAddEventHandler(t *testing.T) {
+	children := []*countingSource{{}, {}}
+	source, err := NewMultiSourceWithErrorPolicy([]Source{children[0], children[1]}, nil, MultiSourceErrorPolicyBestEffort)
+	require.NoError(t, err)
+
+	calls := 0
+	source.AddEventHandler(context.Background(), func() { calls++ })
+
+	for _, child := range children {
+		child.trigger()
+	}
+	assert.Equal(t, 2, calls)
+}
+
+// testMultiSourceErrorPolicyDefaultTargets tests that the best-effort policy applies default targets.
+func testMultiSourceErrorPolicyDefaultTargets(t *testing.T) {
+	failing := new(testutils.MockSource)
+	failing.On("Endpoints").Return([]*endpoint.Endpoint{}, errors.New("failed"))
+	src := new(testutils.MockSource)
+	src.On("Endpoints").Return([]*endpoint.Endpoint{{DNSName: "foo", Targets: endpoint.Targets{"8.8.8.8"}}}, nil)
+
+	source, err := NewMultiSourceWithErrorPolicy([]Source{failing, src}, []string{"127.0.0.1"}, MultiSourceErrorPolicyBestEffort)
+	require.NoError(t, err)
+
+	endpoints, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{{DNSName: "foo", Targets: endpoint.Targets{"127.0.0.1"}}})
+
+	failing.AssertExpectations(t)
+	src.AssertExpectations(t)
+}
+
+// testMultiSourceUnknownErrorPolicy tests that unknown error policies are rejected.
+func testMultiSourceUnknownErrorPolicy(t *testing.T) {
+	_, err := NewMultiSourceWithErrorPolicy(nil, nil, "some")
+	require.EqualError(t, err, `unknown multi source error policy "some"`)
+}
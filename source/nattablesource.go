@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net/netip"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// natTableSource is a Source that translates the private IP targets of its wrapped source
+// to public ones using a static address translation table.
+type natTableSource struct {
+	source Source
+	table  map[string]string
+	strict bool
+}
+
+// NewNATTableSource creates a new natTableSource wrapping the provided Source.
+// Targets missing from the table are kept as they are.
+func NewNATTableSource(source Source, table map[string]string) Source {
+	return &natTableSource{source: source, table: table}
+}
+
+// NewStrictNATTableSource creates a new natTableSource wrapping the provided Source
+// which drops endpoints keeping private targets missing from the table.
+func NewStrictNATTableSource(source Source, table map[string]string) Source {
+	return &natTableSource{source: source, table: table, strict: true}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of the A and AAAA
+// endpoints with their targets translated by exact match. Targets translated to the same
+// address are merged, all other records are passed through untouched.
+func (ms *natTableSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA {
+			result = append(result, ep)
+			continue
+		}
+
+		targets, untranslated := ms.translate(ep.Targets)
+		if untranslated != "" && ms.strict {
+			log.Debugf("Dropping endpoint %s with untranslated private target %s", ep, untranslated)
+			continue
+		}
+		ep = ep.DeepCopy()
+		ep.Targets = targets
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// translate returns the translated targets and the first private target missing from the table, if any.
+func (ms *natTableSource) translate(targets endpoint.Targets) (endpoint.Targets, string) {
+	translated := make(endpoint.Targets, 0, len(targets))
+	untranslated := ""
+	for _, t := range targets {
+		if public, ok := ms.table[t]; ok {
+			translated = append(translated, public)
+			continue
+		}
+		if addr, err := netip.ParseAddr(t); err == nil && addr.IsPrivate() && untranslated == "" {
+			untranslated = t
+		}
+		translated = append(translated, t)
+	}
+	return translated.Dedup(), untranslated
+}
+
+func (ms *natTableSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
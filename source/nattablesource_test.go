@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that natTableSource is a Source
+var _ Source = &natTableSource{}
+
+func TestNATTableSource(t *testing.T) {
+	table := map[string]string{
+		"10.0.0.1": "203.0.113.1",
+		"10.0.0.2": "203.0.113.2",
+		"10.0.0.3": "203.0.113.1",
+		"fd00::1":  "2001:db8::1",
+	}
+
+	for _, tc := range []struct {
+		title       string
+		constructor func(Source, map[string]string) Source
+		endpoints   []*endpoint.Endpoint
+		expected    []*endpoint.Endpoint
+	}{
+		{
+			"fully translated targets",
+			NewStrictNATTableSource,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.2"}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"fd00::1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"203.0.113.1", "203.0.113.2"}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			"targets translated to the same address are merged",
+			NewNATTableSource,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.3"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"203.0.113.1"}},
+			},
+		},
+		{
+			"partially translated targets are kept",
+			NewNATTableSource,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.99", "198.51.100.1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"203.0.113.1", "10.0.0.99", "198.51.100.1"}},
+			},
+		},
+		{
+			"partially translated targets are dropped when strict",
+			NewStrictNATTableSource,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.99"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.2", "198.51.100.1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"203.0.113.2", "198.51.100.1"}},
+			},
+		},
+		{
+			"other record types are passed through",
+			NewStrictNATTableSource,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"10.0.0.1"}},
+				{DNSName: "bar.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"foo.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"10.0.0.1"}},
+				{DNSName: "bar.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"foo.example.org"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].Targets[0]
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := tc.constructor(mockSource, table)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].Targets[0], "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -19,12 +19,21 @@ package source
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"text/template"
+	"time"
 
+	"github.com/miekg/dns"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	kubeinformers "k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -33,15 +42,460 @@ import (
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
+const (
+	// nodeRoleLabelPrefix is the prefix of the well-known labels marking the roles of a node.
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+	// nodeRoleControlPlane is the role of control plane nodes, formerly called master.
+	nodeRoleControlPlane = "control-plane"
+	nodeRoleMaster       = "master"
+
+	// defaultNodeWeight is the weight of nodes without a valid weight label.
+	defaultNodeWeight = 1
+
+	// apiServerServiceNamespace and apiServerServiceName identify the service of the Kubernetes API.
+	apiServerServiceNamespace = "default"
+	apiServerServiceName      = "kubernetes"
+
+	// nodePlaceholderText is the content of the placeholder TXT record emitted when no node matches.
+	nodePlaceholderText = "external-dns/matching-nodes=0"
+)
+
+// reservedLabelKeys are the endpoint labels managed by the registries, which node labels never overwrite.
+var reservedLabelKeys = map[string]bool{
+	endpoint.OwnerLabelKey:         true,
+	endpoint.ResourceLabelKey:      true,
+	endpoint.OwnedRecordLabelKey:   true,
+	endpoint.AWSSDDescriptionLabel: true,
+	endpoint.DualstackLabelKey:     true,
+}
+
+// defaultNodeAddressTypes is the order in which node addresses are looked up by default.
+var defaultNodeAddressTypes = []v1.NodeAddressType{v1.NodeExternalIP, v1.NodeInternalIP}
+
 type nodeSource struct {
 	client           kubernetes.Interface
 	annotationFilter string
 	fqdnTemplate     *template.Template
 	nodeInformer     coreinformers.NodeInformer
+	placeholderName  string
+	cloudAddresses   CloudAddressProvider
+	skipTerminating  bool
+	skipUnavailable  bool
+	containerRuntime string
+	hashRingName     string
+	labelPrefixes    map[string]string
+	internalDNSAlias bool
+	systemInfo       bool
+	systemInfoPrefix string
+	minReadyNodes    int
+	addressTypes     []v1.NodeAddressType
+	zones            map[string]bool
+	excludedRoles    []string
+	excludedTaints   []v1.Taint
+	weightLabel      string
+	acceleratorName  string
+	acceleratorKeys  []string
+	firewallProperty string
+	uptimeTTL        NodeTTLRamp
+	internalFQDN     string
+	externalFQDN     string
+	internalTemplate *template.Template
+	externalTemplate *template.Template
+	virtualNodeKey   string
+	copyPrefixes     []string
+	copyKeys         []string
+	webhook          *nodeWebhook
+	anycastName      string
+	apiServerName    string
+	defaultTTL       endpoint.TTL
+	spotLabel        string
+	spotValue        string
+	spotTTL          endpoint.TTL
+	aggregateName    string
+	aggregateOnly    bool
+	tiers            []NodeTier
+	bastionLabel     string
+	bastionValue     string
+	bastionTargets   endpoint.Targets
+	reverseRecords   bool
+	egressKey        string
+	egressPrefix     string
+	conditionType    v1.NodeConditionType
+	conditionPrefix  string
+	now              func() time.Time
+
+	// lastEndpoints holds the endpoints of the last sync meeting the ready nodes threshold.
+	lastEndpoints []*endpoint.Endpoint
+	lastMutex     sync.Mutex
+}
+
+// CloudAddressProvider looks up node addresses from cloud metadata.
+type CloudAddressProvider interface {
+	// ExternalAddresses returns the external addresses of the given node.
+	ExternalAddresses(ctx context.Context, node *v1.Node) ([]string, error)
+}
+
+// NodeSourceOption allows to configure optional behavior of the node source.
+type NodeSourceOption func(*nodeSource)
+
+// NodeWithPlaceholder makes the node source emit a TXT record with the given name
+// when no node matches, so that the absence of nodes is observable.
+func NodeWithPlaceholder(dnsName string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.placeholderName = dnsName
+	}
+}
+
+// NodeWithCloudAddressProvider makes the node source ask the given provider for the
+// external addresses of nodes whose status does not report any.
+func NodeWithCloudAddressProvider(provider CloudAddressProvider) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.cloudAddresses = provider
+	}
+}
+
+// NodeWithoutTerminating makes the node source skip nodes that are being deleted.
+func NodeWithoutTerminating() NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.skipTerminating = true
+	}
+}
+
+// NodeWithoutUnavailable makes the node source skip nodes that are cordoned or not ready.
+// Nodes which don't report the Ready condition at all are considered not ready and skipped too.
+func NodeWithoutUnavailable() NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.skipUnavailable = true
+	}
+}
+
+// NodeWithContainerRuntime makes the node source publish only nodes whose reported
+// container runtime version starts with the given value, e.g. "containerd" or "containerd://1.6".
+func NodeWithContainerRuntime(runtime string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.containerRuntime = runtime
+	}
+}
+
+// NodeWithHashRing makes the node source publish a TXT record with the given name describing
+// a consistent hash ring of all published nodes. Each value has the form "<hash> <node> <address>",
+// where hash is the 32-bit FNV-1a hash of the node name, and values are ordered by hash.
+func NodeWithHashRing(dnsName string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.hashRingName = dnsName
+	}
+}
+
+// NodeWithLabelProviderSpecific makes the node source turn node labels into provider specific
+// properties. Each key of prefixes is a label key prefix which is replaced by its value to
+// build the property name, e.g. {"dns.example.org/aws-": "aws/"} turns the label
+// "dns.example.org/aws-weight=10" into the property "aws/weight=10".
+func NodeWithLabelProviderSpecific(prefixes map[string]string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.labelPrefixes = prefixes
+	}
+}
+
+// NodeWithInternalDNSAlias makes the node source additionally publish each InternalDNS
+// address of a node as a CNAME pointing to the record derived from the node name.
+func NodeWithInternalDNSAlias() NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.internalDNSAlias = true
+	}
+}
+
+// NodeWithSystemInfo makes the node source publish a TXT record per node holding the kernel
+// version and OS image reported in the node status. The record name is the node record name
+// with the given prefix prepended, so that it doesn't collide with registry TXT records.
+func NodeWithSystemInfo(prefix string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.systemInfo = true
+		ns.systemInfoPrefix = prefix
+	}
+}
+
+// NodeWithMinReadyNodes makes the node source keep returning the endpoints of the last sync
+// while fewer than min of the matching nodes are ready, to avoid draining all records at once.
+func NodeWithMinReadyNodes(min int) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.minReadyNodes = min
+	}
+}
+
+// NodeWithAddressTypePreference makes the node source publish the addresses of the first of
+// the given types a node reports, instead of preferring external over internal addresses.
+// Addresses from a cloud address provider count as external addresses. A Hostname address that
+// is not an IP is published as a CNAME record.
+func NodeWithAddressTypePreference(types []v1.NodeAddressType) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.addressTypes = types
+	}
+}
+
+// NodeWithZones makes the node source publish only nodes whose topology.kubernetes.io/zone
+// label, or the deprecated failure-domain.beta.kubernetes.io/zone label, is one of the given zones.
+// An empty list publishes nodes of all zones.
+func NodeWithZones(zones []string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.zones = map[string]bool{}
+		for _, zone := range zones {
+			ns.zones[zone] = true
+		}
+	}
+}
+
+// NodeWithoutRole makes the node source skip nodes carrying the node-role.kubernetes.io/<role>
+// label. Excluding either the control-plane or the legacy master role excludes both labels.
+// The option can be given multiple times to exclude several roles.
+func NodeWithoutRole(role string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.excludedRoles = append(ns.excludedRoles, role)
+	}
+}
+
+// NodeWithoutTaint makes the node source skip nodes carrying a taint with the given key and effect,
+// or with any effect if effect is empty. The option can be given multiple times to exclude several taints.
+func NodeWithoutTaint(key string, effect v1.TaintEffect) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.excludedTaints = append(ns.excludedTaints, v1.Taint{Key: key, Effect: effect})
+	}
+}
+
+// NodeWithBastion makes the records of the nodes of a restricted pool, recognized by the label
+// with the given key and value, point at the given bastion targets instead of the node addresses.
+// The targets are either IP addresses or a hostname and take precedence over the target annotation.
+func NodeWithBastion(label, value string, targets ...string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.bastionLabel = label
+		ns.bastionValue = value
+		ns.bastionTargets = targets
+	}
+}
+
+// NodeWithEgressRecord makes the node source publish a record per node pointing at the egress
+// IPs assigned to it, e.g. for outbound allowlisting. The egress IPs are read from the comma
+// separated annotation with the given key, or the label with that key if there is no such
+// annotation. The record name is the node record name with the given prefix prepended.
+func NodeWithEgressRecord(key, prefix string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.egressKey = key
+		ns.egressPrefix = prefix
+	}
+}
+
+// NodeWithConditionRecord makes the node source publish a TXT record per node reflecting the
+// state of the node condition of the given type, e.g. a condition set by the node problem
+// detector, so it can be monitored through DNS. The record holds the status and, if set, the
+// reason of the condition and is named after the node record with the given prefix prepended.
+// Nodes not reporting the condition get no such record.
+func NodeWithConditionRecord(conditionType v1.NodeConditionType, prefix string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.conditionType = conditionType
+		ns.conditionPrefix = prefix
+	}
+}
+
+// NodeWithReverseRecords makes the node source additionally publish a PTR record for each
+// address of a node, named after the in-addr.arpa or ip6.arpa name of the address and
+// pointing at the node record.
+func NodeWithReverseRecords() NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.reverseRecords = true
+	}
+}
+
+// NodeWithWeightLabel makes the node source publish a weighted record per node instead of
+// merging the addresses of nodes sharing a DNS name. The node name is used as set identifier
+// and the weight is read from the given node label. Nodes without a valid weight label get
+// the default weight of 1.
+func NodeWithWeightLabel(label string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.weightLabel = label
+	}
+}
+
+// NodeWithAcceleratorRecord makes the node source additionally publish a record with the given
+// name holding the addresses of all nodes carrying at least one of the given accelerator labels,
+// e.g. "nvidia.com/gpu.present", regardless of the label values.
+func NodeWithAcceleratorRecord(dnsName string, labelKeys ...string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.acceleratorName = dnsName
+		ns.acceleratorKeys = labelKeys
+	}
+}
+
+// NodeWithFirewallTags makes the node source read the comma separated firewall tags of a node
+// from the external-dns.alpha.kubernetes.io/firewall-tags annotation and set them as the provider
+// specific property with the given name, for providers associating records with security policies.
+func NodeWithFirewallTags(property string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.firewallProperty = property
+	}
+}
+
+// NodeTTLRamp returns the TTL of the records of a node which has been ready for the given time.
+type NodeTTLRamp func(uptime time.Duration) endpoint.TTL
+
+// LinearNodeTTLRamp returns a NodeTTLRamp growing linearly from min for nodes which just became
+// ready to max for nodes which have been ready for at least period.
+func LinearNodeTTLRamp(min, max endpoint.TTL, period time.Duration) NodeTTLRamp {
+	return func(uptime time.Duration) endpoint.TTL {
+		if uptime <= 0 {
+			return min
+		}
+		if uptime >= period {
+			return max
+		}
+		return min + endpoint.TTL(float64(max-min)*float64(uptime)/float64(period))
+	}
+}
+
+// NodeWithUptimeTTL makes the node source derive the TTL of the records of a node from the time
+// since it became ready, so that records of newly joined and flapping nodes are short-lived.
+// Nodes which are not ready have an uptime of zero. The current time is read from now, which
+// defaults to time.Now if nil. A TTL set by annotation takes precedence over the ramp.
+func NodeWithUptimeTTL(ramp NodeTTLRamp, now func() time.Time) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.uptimeTTL = ramp
+		ns.now = now
+		if ns.now == nil {
+			ns.now = time.Now
+		}
+	}
+}
+
+// NodeWithAddressTypeTemplates makes the node source publish the InternalIP and ExternalIP
+// addresses of a node under separate names rendered from the given FQDN templates, instead of
+// a single record. Address types whose template is empty or which a node doesn't report are skipped.
+func NodeWithAddressTypeTemplates(internalTemplate, externalTemplate string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.internalFQDN = internalTemplate
+		ns.externalFQDN = externalTemplate
+	}
+}
+
+// NodeWithVirtualNodeAnnotation makes the node source read the targets of virtual nodes, e.g.
+// the ones registered by virtual-kubelet, from the annotation with the given key. Nodes count as
+// virtual when their status reports no addresses. Virtual nodes without a valid annotation are
+// skipped instead of failing the sync.
+func NodeWithVirtualNodeAnnotation(key string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.virtualNodeKey = key
+	}
+}
+
+// NodeWithEndpointLabels makes the node source copy the node labels whose key starts with one of
+// the given prefixes or equals one of the given keys into the labels of the node endpoints, so that
+// downstream tooling can route records by them. Labels reserved by the registries are never copied.
+func NodeWithEndpointLabels(prefixes, keys []string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.copyPrefixes = prefixes
+		ns.copyKeys = keys
+	}
+}
+
+// NodeWithWebhook makes the node source publish only nodes approved by the webhook at the given
+// URL. The name, labels and annotations of each node are posted as JSON and the webhook must reply
+// with {"allowed": true} to publish it. Requests time out after timeout and decisions are cached
+// for cacheTTL. If the webhook fails, the last known decision is used and unknown nodes are skipped.
+func NodeWithWebhook(url string, timeout, cacheTTL time.Duration) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.webhook = newNodeWebhook(url, timeout, cacheTTL)
+	}
+}
+
+// NodeWithAnycastRecord makes the node source additionally publish a record with the given name
+// holding the anycast addresses announced by the nodes, read from the comma separated
+// external-dns.alpha.kubernetes.io/anycast-addresses annotation. Addresses shared by nodes of
+// different regions are published once. IPv4 and IPv6 addresses go to A and AAAA records.
+func NodeWithAnycastRecord(dnsName string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.anycastName = dnsName
+	}
+}
+
+// NodeWithDefaultTTL sets the TTL of the records of nodes without a valid TTL annotation,
+// instead of leaving it unconfigured for the provider to choose.
+func NodeWithDefaultTTL(ttl endpoint.TTL) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.defaultTTL = ttl
+	}
+}
+
+// NodeWithSpotTTL sets the TTL of the records of spot or preemptible nodes, recognized by the
+// label with the given key and value, e.g. "eks.amazonaws.com/capacityType" and "SPOT", so that
+// their records expire quickly when they disappear. A TTL set by annotation takes precedence.
+func NodeWithSpotTTL(label, value string, ttl endpoint.TTL) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.spotLabel = label
+		ns.spotValue = value
+		ns.spotTTL = ttl
+	}
+}
+
+// NodeWithAggregateRecord makes the node source additionally publish a round-robin record with
+// the given name pointing at the addresses of all matching nodes, deduplicated and sorted. If
+// replace is true, the per-node address records are no longer published. Targets which are not
+// IP addresses are not aggregated, and no record is published if no node matches.
+func NodeWithAggregateRecord(dnsName string, replace bool) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.aggregateName = dnsName
+		ns.aggregateOnly = replace
+	}
+}
+
+// NodeWithAPIServerRecord makes the node source additionally publish a record with the given name
+// pointing at the control plane addresses of the Kubernetes API, read from the endpoints of the
+// default/kubernetes service.
+func NodeWithAPIServerRecord(dnsName string) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.apiServerName = dnsName
+	}
+}
+
+// NodeTier describes a tier of nodes, e.g. gold, silver or bronze, published as a single record.
+type NodeTier struct {
+	// DNSName is the name of the record holding the addresses of the nodes of the tier.
+	DNSName string
+	// Labels are the labels a node must have, with the given values, to belong to the tier.
+	Labels map[string]string
+	// Taints are the keys of the taints a node must have to belong to the tier.
+	Taints []string
+}
+
+// matches returns true if the node has all labels and taints of the tier.
+func (tier NodeTier) matches(node *v1.Node) bool {
+	for key, value := range tier.Labels {
+		if nodeValue, ok := node.Labels[key]; !ok || nodeValue != value {
+			return false
+		}
+	}
+	for _, key := range tier.Taints {
+		tainted := false
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == key {
+				tainted = true
+				break
+			}
+		}
+		if !tainted {
+			return false
+		}
+	}
+	return true
+}
+
+// NodeWithTierRecords makes the node source additionally publish a record per tier holding the
+// addresses of the nodes of the tier. A node belongs to the first tier it matches, so tiers are
+// best ordered from the most to the least specific. Tiers without nodes are not published.
+func NodeWithTierRecords(tiers ...NodeTier) NodeSourceOption {
+	return func(ns *nodeSource) {
+		ns.tiers = tiers
+	}
 }
 
 // NewNodeSource creates a new nodeSource with the given config.
-func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotationFilter, fqdnTemplate string) (Source, error) {
+func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotationFilter, fqdnTemplate string, opts ...NodeSourceOption) (Source, error) {
 	tmpl, err := parseTemplate(fqdnTemplate)
 	if err != nil {
 		return nil, err
@@ -64,20 +518,40 @@ func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotat
 	informerFactory.Start(ctx.Done())
 
 	// wait for the local cache to be populated.
-	if err := waitForCacheSync(context.Background(), informerFactory); err != nil {
+	if err := waitForCacheSync(ctx, informerFactory); err != nil {
 		return nil, err
 	}
 
-	return &nodeSource{
+	ns := &nodeSource{
 		client:           kubeClient,
 		annotationFilter: annotationFilter,
 		fqdnTemplate:     tmpl,
 		nodeInformer:     nodeInformer,
-	}, nil
+		addressTypes:     defaultNodeAddressTypes,
+	}
+
+	for _, opt := range opts {
+		opt(ns)
+	}
+
+	if ns.internalTemplate, err = parseTemplate(ns.internalFQDN); err != nil {
+		return nil, err
+	}
+	if ns.externalTemplate, err = parseTemplate(ns.externalFQDN); err != nil {
+		return nil, err
+	}
+
+	return ns, nil
 }
 
 // Endpoints returns endpoint objects for each service that should be processed.
+// The nodes are read from the informer cache, and the context is checked before each node
+// so that a cancelled or expired context stops slow webhook and cloud lookups promptly.
 func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	nodes, err := ns.nodeInformer.Lister().List(labels.Everything())
 	if err != nil {
 		return nil, err
@@ -87,11 +561,24 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 	if err != nil {
 		return nil, err
 	}
+	if ns.webhook != nil {
+		ns.webhook.prune(nodes)
+	}
 
-	endpoints := map[string]*endpoint.Endpoint{}
+	endpoints := map[endpointKey]*endpoint.Endpoint{}
+	ring := []hashRingMember{}
+	anycast := endpoint.Targets{}
+	aggregate := endpoint.Targets{}
+	tiers := make([]endpoint.Targets, len(ns.tiers))
+	readyNodes := 0
+	matchedNodes := 0
 
 	// create endpoints for all nodes
 	for _, node := range nodes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// Check controller annotation to see if we are responsible.
 		controller, ok := node.Annotations[controllerAnnotationKey]
 		if ok && controller != controllerAnnotationValue {
@@ -100,12 +587,30 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 			continue
 		}
 
+		if ns.skipNode(node) {
+			continue
+		}
+
+		if ns.webhook != nil && !ns.webhook.allowed(ctx, node) {
+			log.Debugf("Skipping node %s because it was not approved by the webhook", node.Name)
+			continue
+		}
+
 		log.Debugf("creating endpoint for node %s", node.Name)
 
 		ttl, err := getTTLFromAnnotations(node.Annotations)
 		if err != nil {
 			log.Warn(err)
 		}
+		if ns.spotLabel != "" && !ttl.IsConfigured() && node.Labels[ns.spotLabel] == ns.spotValue {
+			ttl = ns.spotTTL
+		}
+		if ns.uptimeTTL != nil && !ttl.IsConfigured() {
+			ttl = ns.uptimeTTL(ns.nodeUptime(node))
+		}
+		if !ttl.IsConfigured() {
+			ttl = ns.defaultTTL
+		}
 
 		// create new endpoint with the information we already have
 		ep := &endpoint.Endpoint{
@@ -113,36 +618,168 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 			RecordTTL:  ttl,
 		}
 
-		if ns.fqdnTemplate != nil {
-			hostnames, err := execTemplate(ns.fqdnTemplate, node)
+		hostnames := getNodeHostnamesFromAnnotations(node)
+		if len(hostnames) > 0 {
+			ep.DNSName = hostnames[0]
+		} else {
+			ep.DNSName, err = nodeHostname(ns.fqdnTemplate, node)
 			if err != nil {
 				return nil, err
 			}
-			hostname := ""
-			if len(hostnames) > 0 {
-				hostname = hostnames[0]
-			}
-			ep.DNSName = hostname
-			log.Debugf("applied template for %s, converting to %s", node.Name, ep.DNSName)
-		} else {
-			ep.DNSName = node.Name
-			log.Debugf("not applying template for %s", node.Name)
 		}
 
-		addrs, err := ns.nodeAddresses(node)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get node address from %s: %s", node.Name, err.Error())
+		overrides, ok := ns.overrideTargets(node)
+		if !ok {
+			continue
 		}
 
-		ep.Targets = endpoint.Targets(addrs)
-		ep.Labels = endpoint.NewLabels()
+		// only nodes which pass all checks count as matches, see NodeWithPlaceholder
+		matchedNodes++
+		if isNodeReady(node) {
+			readyNodes++
+		}
 
-		log.Debugf("adding endpoint %s", ep)
-		if _, ok := endpoints[ep.DNSName]; ok {
-			endpoints[ep.DNSName].Targets = append(endpoints[ep.DNSName].Targets, ep.Targets...)
+		targets := overrides
+		if len(targets) > 0 {
+			ep.RecordType = suitableType(targets[0])
 		} else {
-			endpoints[ep.DNSName] = ep
+			addrs, addrType, err := ns.nodeAddresses(ctx, node, ns.addressTypes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get node address from %s: %s", node.Name, err.Error())
+			}
+			targets = endpoint.Targets(addrs)
+			// a hostname address is usually a name rather than an IP, which can only be aliased
+			if addrType == v1.NodeHostName && suitableType(addrs[0]) == endpoint.RecordTypeCNAME {
+				ep.RecordType = endpoint.RecordTypeCNAME
+				targets = targets[:1]
+			}
+		}
+
+		ep.Targets = targets
+		ep.Labels = ns.endpointLabels(node)
+		ep.ProviderSpecific = ns.labelProviderSpecific(node)
+		if ns.weightLabel != "" {
+			ep.SetIdentifier = node.Name
+			ep.ProviderSpecific = append(ep.ProviderSpecific, endpoint.ProviderSpecificProperty{
+				Name:  weightProviderSpecificKey,
+				Value: strconv.Itoa(ns.nodeWeight(node)),
+			})
+		}
+		if ns.firewallProperty != "" {
+			if tags := getFirewallTagsFromAnnotations(node.Annotations); tags != "" {
+				ep.ProviderSpecific = append(ep.ProviderSpecific, endpoint.ProviderSpecificProperty{
+					Name:  ns.firewallProperty,
+					Value: tags,
+				})
+			}
+		}
+
+		if ns.aggregateName != "" {
+			aggregate = append(aggregate, ep.Targets...)
+		}
+
+		for i, tier := range ns.tiers {
+			if tier.matches(node) {
+				tiers[i] = append(tiers[i], ep.Targets...)
+				break
+			}
+		}
+
+		switch {
+		case ns.aggregateOnly:
+			log.Debugf("replacing endpoint %s by aggregate endpoint %s", ep, ns.aggregateName)
+		case ns.internalTemplate != nil || ns.externalTemplate != nil:
+			split, err := ns.addressTypeEndpoints(ctx, node, ep, overrides)
+			if err != nil {
+				return nil, err
+			}
+			for _, sep := range split {
+				log.Debugf("adding endpoint %s", sep)
+				mergeEndpoint(endpoints, sep)
+				ns.mergeReverseEndpoints(endpoints, sep)
+			}
+		default:
+			log.Debugf("adding endpoint %s", ep)
+			mergeEndpoint(endpoints, ep)
+			ns.mergeReverseEndpoints(endpoints, ep)
+			for i := 1; i < len(hostnames); i++ {
+				alias := ep.DeepCopy()
+				alias.DNSName = hostnames[i]
+				log.Debugf("adding endpoint %s", alias)
+				mergeEndpoint(endpoints, alias)
+			}
+		}
+
+		if ns.anycastName != "" {
+			anycast = append(anycast, getAnycastAddressesFromAnnotations(node)...)
+		}
+
+		if ns.acceleratorName != "" && ns.hasAccelerator(node) {
+			mergeEndpoint(endpoints, endpoint.NewEndpointWithTTL(ns.acceleratorName, ep.RecordType, ttl, ep.Targets...))
+		}
+
+		if ns.internalDNSAlias {
+			for _, addr := range node.Status.Addresses {
+				if addr.Type != v1.NodeInternalDNS || addr.Address == ep.DNSName {
+					continue
+				}
+				mergeEndpoint(endpoints, endpoint.NewEndpointWithTTL(addr.Address, endpoint.RecordTypeCNAME, ttl, ep.DNSName))
+			}
+		}
+
+		if ns.systemInfo {
+			info := node.Status.NodeInfo
+			mergeEndpoint(endpoints, endpoint.NewEndpointWithTTL(ns.systemInfoPrefix+ep.DNSName, endpoint.RecordTypeTXT, ttl,
+				"kernel-version="+info.KernelVersion, "os-image="+info.OSImage))
+		}
+
+		if ns.egressKey != "" {
+			for _, egress := range addressEndpoints(ns.egressPrefix+ep.DNSName, getEgressAddresses(node, ns.egressKey)) {
+				egress.RecordTTL = ttl
+				mergeEndpoint(endpoints, egress)
+			}
+		}
+
+		if ns.conditionType != "" {
+			if targets := nodeConditionTargets(node, ns.conditionType); len(targets) > 0 {
+				mergeEndpoint(endpoints, endpoint.NewEndpointWithTTL(ns.conditionPrefix+ep.DNSName, endpoint.RecordTypeTXT, ttl, targets...))
+			}
+		}
+
+		if ns.hashRingName != "" {
+			ring = append(ring, hashRingMember{hash: hashNodeName(node.Name), node: node.Name, target: ep.Targets[0]})
 		}
+
+		for _, record := range annotationRecordEndpoints(node.Annotations, ep.DNSName, ttl) {
+			mergeEndpoint(endpoints, record)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, ep := range addressEndpoints(ns.aggregateName, aggregate) {
+		mergeEndpoint(endpoints, ep)
+	}
+
+	for i, tier := range ns.tiers {
+		for _, ep := range addressEndpoints(tier.DNSName, tiers[i]) {
+			mergeEndpoint(endpoints, ep)
+		}
+	}
+
+	for _, ep := range addressEndpoints(ns.anycastName, anycast) {
+		mergeEndpoint(endpoints, ep)
+	}
+
+	if ns.apiServerName != "" {
+		for _, ep := range ns.apiServerEndpoints(ctx) {
+			mergeEndpoint(endpoints, ep)
+		}
+	}
+
+	if ns.hashRingName != "" && len(ring) > 0 {
+		mergeEndpoint(endpoints, hashRingEndpoint(ns.hashRingName, ring))
 	}
 
 	endpointsSlice := []*endpoint.Endpoint{}
@@ -150,33 +787,540 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 		endpointsSlice = append(endpointsSlice, ep)
 	}
 
+	// endpoints not tied to nodes, like the api server record, do not count as matches
+	if matchedNodes == 0 && ns.placeholderName != "" {
+		log.Debugf("no matching nodes, adding placeholder endpoint %s", ns.placeholderName)
+		if placeholder := endpoint.NewEndpoint(ns.placeholderName, endpoint.RecordTypeTXT, nodePlaceholderText); placeholder != nil {
+			endpointsSlice = append(endpointsSlice, placeholder)
+		} else {
+			log.Warnf("Skipping invalid placeholder endpoint %s", ns.placeholderName)
+		}
+	}
+
+	// the ready nodes gate goes last, so that the placeholder never replaces kept endpoints
+	if ns.minReadyNodes > 0 {
+		return ns.gateByReadyNodes(endpointsSlice, readyNodes), nil
+	}
+
 	return endpointsSlice, nil
 }
 
+// gateByReadyNodes returns the given endpoints and remembers them if enough nodes are ready,
+// otherwise it returns the endpoints remembered last, if any.
+func (ns *nodeSource) gateByReadyNodes(endpoints []*endpoint.Endpoint, readyNodes int) []*endpoint.Endpoint {
+	ns.lastMutex.Lock()
+	defer ns.lastMutex.Unlock()
+
+	if readyNodes < ns.minReadyNodes && ns.lastEndpoints != nil {
+		log.Warnf("Only %d nodes are ready, required: %d, keeping endpoints of the last sync", readyNodes, ns.minReadyNodes)
+		return copyEndpoints(ns.lastEndpoints)
+	}
+
+	if readyNodes >= ns.minReadyNodes {
+		ns.lastEndpoints = copyEndpoints(endpoints)
+	}
+	return endpoints
+}
+
+// copyEndpoints returns a deep copy of the given endpoints.
+func copyEndpoints(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		result = append(result, ep.DeepCopy())
+	}
+	return result
+}
+
+// isNodeReady returns true if the node reports the Ready condition as true.
+func isNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// nodeHostname returns the DNS name of the node rendered from the given template,
+// or the node name if there is no template.
+func nodeHostname(tmpl *template.Template, node *v1.Node) (string, error) {
+	if tmpl == nil {
+		log.Debugf("not applying template for %s", node.Name)
+		return node.Name, nil
+	}
+	hostnames, err := execTemplate(tmpl, node)
+	if err != nil {
+		return "", err
+	}
+	hostname := ""
+	if len(hostnames) > 0 {
+		hostname = hostnames[0]
+	}
+	log.Debugf("applied template for %s, converting to %s", node.Name, hostname)
+	return hostname, nil
+}
+
+// addressTypeEndpoints returns copies of ep holding the InternalIP and ExternalIP addresses of
+// the node, named after the respective templates. The override targets of the node, if any,
+// replace the addresses of both records.
+func (ns *nodeSource) addressTypeEndpoints(ctx context.Context, node *v1.Node, ep *endpoint.Endpoint, overrides endpoint.Targets) ([]*endpoint.Endpoint, error) {
+	var result []*endpoint.Endpoint
+	for _, split := range []struct {
+		tmpl     *template.Template
+		addrType v1.NodeAddressType
+	}{
+		{ns.internalTemplate, v1.NodeInternalIP},
+		{ns.externalTemplate, v1.NodeExternalIP},
+	} {
+		if split.tmpl == nil {
+			continue
+		}
+		targets := overrides
+		if len(targets) == 0 {
+			addrs, _, err := ns.nodeAddresses(ctx, node, []v1.NodeAddressType{split.addrType})
+			if err != nil {
+				log.Debugf("Skipping %s record of node %s without such addresses", split.addrType, node.Name)
+				continue
+			}
+			targets = endpoint.Targets(addrs)
+		}
+		hostname, err := nodeHostname(split.tmpl, node)
+		if err != nil {
+			return nil, err
+		}
+		splitEp := ep.DeepCopy()
+		splitEp.DNSName = hostname
+		splitEp.RecordType = suitableType(targets[0])
+		splitEp.Targets = append(endpoint.Targets{}, targets...)
+		result = append(result, splitEp)
+	}
+	return result, nil
+}
+
+// nodeUptime returns the time since the node became ready, or zero if it is not ready.
+func (ns *nodeSource) nodeUptime(node *v1.Node) time.Duration {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+			if uptime := ns.now().Sub(condition.LastTransitionTime.Time); uptime > 0 {
+				return uptime
+			}
+		}
+	}
+	return 0
+}
+
+// skipNode returns true if the node must not be published according to the
+// configured options, logging the reason.
+func (ns *nodeSource) skipNode(node *v1.Node) bool {
+	if ns.skipTerminating && node.DeletionTimestamp != nil {
+		log.Debugf("Skipping node %s because it is being deleted", node.Name)
+		return true
+	}
+	if ns.skipUnavailable && node.Spec.Unschedulable {
+		log.Debugf("Skipping node %s because it is unschedulable", node.Name)
+		return true
+	}
+	if ns.skipUnavailable && !isNodeReady(node) {
+		log.Debugf("Skipping node %s because it is not ready", node.Name)
+		return true
+	}
+	if ns.containerRuntime != "" && !strings.HasPrefix(node.Status.NodeInfo.ContainerRuntimeVersion, ns.containerRuntime) {
+		log.Debugf("Skipping node %s because container runtime does not match, found: %s, required: %s",
+			node.Name, node.Status.NodeInfo.ContainerRuntimeVersion, ns.containerRuntime)
+		return true
+	}
+	if role, ok := ns.excludedRole(node); ok {
+		log.Debugf("Skipping node %s because it has the excluded role %s", node.Name, role)
+		return true
+	}
+	if taint, ok := ns.excludedTaint(node); ok {
+		log.Debugf("Skipping node %s because it has the excluded taint %s", node.Name, taint.ToString())
+		return true
+	}
+	if len(ns.zones) > 0 && !ns.zones[nodeZone(node)] {
+		log.Debugf("Skipping node %s because zone %q is not allowed", node.Name, nodeZone(node))
+		return true
+	}
+	return false
+}
+
+// excludedRole returns the first excluded role the node carries.
+func (ns *nodeSource) excludedRole(node *v1.Node) (string, bool) {
+	for _, role := range ns.excludedRoles {
+		roles := []string{role}
+		if role == nodeRoleControlPlane || role == nodeRoleMaster {
+			roles = []string{nodeRoleControlPlane, nodeRoleMaster}
+		}
+		for _, r := range roles {
+			if _, ok := node.Labels[nodeRoleLabelPrefix+r]; ok {
+				return r, true
+			}
+		}
+	}
+	return "", false
+}
+
+// excludedTaint returns the first taint of the node matching an excluded taint.
+func (ns *nodeSource) excludedTaint(node *v1.Node) (v1.Taint, bool) {
+	for _, excluded := range ns.excludedTaints {
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == excluded.Key && (excluded.Effect == "" || taint.Effect == excluded.Effect) {
+				return taint, true
+			}
+		}
+	}
+	return v1.Taint{}, false
+}
+
+// hasAccelerator returns true if the node carries one of the accelerator labels.
+func (ns *nodeSource) hasAccelerator(node *v1.Node) bool {
+	for _, key := range ns.acceleratorKeys {
+		if _, ok := node.Labels[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeWeight returns the weight of the node from its weight label.
+func (ns *nodeSource) nodeWeight(node *v1.Node) int {
+	value, ok := node.Labels[ns.weightLabel]
+	if !ok {
+		return defaultNodeWeight
+	}
+	weight, err := strconv.Atoi(value)
+	if err != nil || weight < 0 {
+		log.Warnf("Invalid weight %q of node %s, using default weight %d", value, node.Name, defaultNodeWeight)
+		return defaultNodeWeight
+	}
+	return weight
+}
+
+// nodeZone returns the zone of the node from its topology labels.
+func nodeZone(node *v1.Node) string {
+	if zone, ok := node.Labels[v1.LabelTopologyZone]; ok {
+		return zone
+	}
+	return node.Labels[v1.LabelFailureDomainBetaZone]
+}
+
+// endpointLabels returns the labels of the node endpoints holding the node labels to copy.
+func (ns *nodeSource) endpointLabels(node *v1.Node) endpoint.Labels {
+	epLabels := endpoint.NewLabels()
+	for key, value := range node.Labels {
+		if reservedLabelKeys[key] {
+			continue
+		}
+		for _, copyKey := range ns.copyKeys {
+			if key == copyKey {
+				epLabels[key] = value
+			}
+		}
+		for _, prefix := range ns.copyPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				epLabels[key] = value
+			}
+		}
+	}
+	return epLabels
+}
+
+// labelProviderSpecific returns the provider specific properties derived from the node labels,
+// ordered by name.
+func (ns *nodeSource) labelProviderSpecific(node *v1.Node) endpoint.ProviderSpecific {
+	var providerSpecific endpoint.ProviderSpecific
+	for key, value := range node.Labels {
+		for labelPrefix, propertyPrefix := range ns.labelPrefixes {
+			if strings.HasPrefix(key, labelPrefix) {
+				providerSpecific = append(providerSpecific, endpoint.ProviderSpecificProperty{
+					Name:  propertyPrefix + strings.TrimPrefix(key, labelPrefix),
+					Value: value,
+				})
+			}
+		}
+	}
+	sort.Slice(providerSpecific, func(i, j int) bool {
+		return providerSpecific[i].Name < providerSpecific[j].Name
+	})
+	return providerSpecific
+}
+
+// hashRingMember is a node placed on a consistent hash ring.
+type hashRingMember struct {
+	hash   uint32
+	node   string
+	target string
+}
+
+// hashNodeName returns the position of a node on the hash ring.
+func hashNodeName(name string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return h.Sum32()
+}
+
+// hashRingEndpoint returns the TXT endpoint describing the ring, with its members ordered
+// by hash and node name so that the record is identical across syncs.
+func hashRingEndpoint(dnsName string, ring []hashRingMember) *endpoint.Endpoint {
+	sort.Slice(ring, func(i, j int) bool {
+		if ring[i].hash != ring[j].hash {
+			return ring[i].hash < ring[j].hash
+		}
+		return ring[i].node < ring[j].node
+	})
+
+	targets := make([]string, 0, len(ring))
+	for _, member := range ring {
+		targets = append(targets, fmt.Sprintf("%08x %s %s", member.hash, member.node, member.target))
+	}
+	return endpoint.NewEndpoint(dnsName, endpoint.RecordTypeTXT, targets...)
+}
+
+// endpointKey identifies the record set an endpoint belongs to.
+type endpointKey struct {
+	dnsName       string
+	recordType    string
+	setIdentifier string
+}
+
+// mergeEndpoint adds ep to endpoints, appending its targets to an already present
+// endpoint with the same DNS name and record type. A nil endpoint, as returned by
+// endpoint.NewEndpoint for invalid DNS names, is skipped.
+func mergeEndpoint(endpoints map[endpointKey]*endpoint.Endpoint, ep *endpoint.Endpoint) {
+	if ep == nil {
+		log.Warn("Skipping endpoint with invalid DNS name")
+		return
+	}
+	key := endpointKey{dnsName: ep.DNSName, recordType: ep.RecordType, setIdentifier: ep.SetIdentifier}
+	if existing, ok := endpoints[key]; ok {
+		existing.Targets = append(existing.Targets, ep.Targets...)
+		return
+	}
+	endpoints[key] = ep
+}
+
 func (ns *nodeSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for node")
+
+	// Right now there is no way to remove event handler from informer, see:
+	// https://github.com/kubernetes/kubernetes/issues/79610
+	ns.nodeInformer.Informer().AddEventHandler(eventHandlerFunc(handler))
 }
 
-// nodeAddress returns node's externalIP and if that's not found, node's internalIP
-// basically what k8s.io/kubernetes/pkg/util/node.GetPreferredNodeAddress does
-func (ns *nodeSource) nodeAddresses(node *v1.Node) ([]string, error) {
-	addresses := map[v1.NodeAddressType][]string{
-		v1.NodeExternalIP: {},
-		v1.NodeInternalIP: {},
+// getFirewallTagsFromAnnotations gets the firewall tags from the optional "firewall-tags" annotation,
+// with whitespace and empty tags removed. Returns an empty string if there are none.
+func getFirewallTagsFromAnnotations(annotations map[string]string) string {
+	tags := []string{}
+	for _, tag := range strings.Split(annotations[firewallTagsAnnotationKey], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return strings.Join(tags, ",")
+}
+
+// getAnycastAddressesFromAnnotations gets the anycast addresses of the node from the optional
+// "anycast-addresses" annotation. Values which are not IP addresses are logged and ignored.
+func getAnycastAddressesFromAnnotations(node *v1.Node) endpoint.Targets {
+	addresses := endpoint.Targets{}
+	for _, address := range getTargetsFromAnnotation(node.Annotations, anycastAnnotationKey) {
+		if net.ParseIP(address) == nil {
+			log.Warnf("Ignoring invalid anycast address %q of node %s", address, node.Name)
+			continue
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// getEgressAddresses gets the egress IPs of the node from the annotation or label with the
+// given key. Values which are not IP addresses are logged and ignored.
+func getEgressAddresses(node *v1.Node, key string) endpoint.Targets {
+	values := node.Annotations
+	if _, ok := values[key]; !ok {
+		values = node.Labels
+	}
+	addresses := endpoint.Targets{}
+	for _, address := range getTargetsFromAnnotation(values, key) {
+		if net.ParseIP(address) == nil {
+			log.Warnf("Ignoring invalid egress IP %q of node %s", address, node.Name)
+			continue
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// mergeReverseEndpoints adds a PTR endpoint pointing at the node endpoint for each of its IP
+// targets to endpoints, if reverse records are enabled.
+func (ns *nodeSource) mergeReverseEndpoints(endpoints map[endpointKey]*endpoint.Endpoint, ep *endpoint.Endpoint) {
+	if !ns.reverseRecords {
+		return
+	}
+	for _, target := range ep.Targets {
+		name, err := reverseName(target)
+		if err != nil {
+			continue
+		}
+		ptr := endpoint.NewEndpointWithTTL(name, endpoint.RecordTypePTR, ep.RecordTTL, ep.DNSName)
+		if ptr == nil {
+			log.Warnf("Skipping invalid PTR endpoint %s", name)
+			continue
+		}
+		mergeEndpoint(endpoints, ptr)
+	}
+}
+
+// nodeConditionTargets returns the TXT record values describing the condition of the given
+// type of a node, or nothing if the node does not report that condition.
+func nodeConditionTargets(node *v1.Node, conditionType v1.NodeConditionType) []string {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != conditionType {
+			continue
+		}
+		targets := []string{"status=" + string(condition.Status)}
+		if condition.Reason != "" {
+			targets = append(targets, "reason="+condition.Reason)
+		}
+		return targets
+	}
+	return nil
+}
+
+// reverseName returns the in-addr.arpa name of an IPv4 address or the ip6.arpa name of an IPv6 address.
+func reverseName(address string) (string, error) {
+	name, err := dns.ReverseAddr(address)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(name, "."), nil
+}
+
+// addressEndpoints returns the A and AAAA endpoints with the given name holding the
+// deduplicated addresses, ordered so that the records are identical across syncs.
+func addressEndpoints(dnsName string, addresses endpoint.Targets) []*endpoint.Endpoint {
+	var endpoints []*endpoint.Endpoint
+	for family, recordType := range map[int]string{4: endpoint.RecordTypeA, 6: endpoint.RecordTypeAAAA} {
+		targets := addresses.FilterByIPFamily(family).Dedup()
+		if len(targets) == 0 {
+			continue
+		}
+		sort.Sort(targets)
+		ep := endpoint.NewEndpoint(dnsName, recordType, targets...)
+		if ep == nil {
+			log.Warnf("Skipping invalid %s endpoint %s", recordType, dnsName)
+			continue
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+// apiServerEndpoints returns the endpoints pointing at the addresses of the default/kubernetes service.
+// Failures to read the service endpoints are logged and no endpoints are returned.
+func (ns *nodeSource) apiServerEndpoints(ctx context.Context) []*endpoint.Endpoint {
+	apiEndpoints, err := ns.client.CoreV1().Endpoints(apiServerServiceNamespace).Get(ctx, apiServerServiceName, metav1.GetOptions{})
+	if err != nil {
+		log.Warnf("Failed to get endpoints of service %s/%s: %v", apiServerServiceNamespace, apiServerServiceName, err)
+		return nil
+	}
+
+	addresses := endpoint.Targets{}
+	for _, subset := range apiEndpoints.Subsets {
+		for _, addr := range subset.Addresses {
+			addresses = append(addresses, addr.IP)
+		}
+	}
+	return addressEndpoints(ns.apiServerName, addresses)
+}
+
+// getNodeHostnamesFromAnnotations gets the DNS names overriding the FQDN template from the optional
+// "hostname" annotation of the node. Names which are not valid hostnames are logged and ignored.
+func getNodeHostnamesFromAnnotations(node *v1.Node) []string {
+	var hostnames []string
+	for _, hostname := range getHostnamesFromAnnotations(node.Annotations) {
+		hostname = strings.TrimSuffix(hostname, ".")
+		if hostname == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(strings.ToLower(hostname)); len(errs) > 0 {
+			log.Warnf("Ignoring invalid hostname %q of node %s: %s", hostname, node.Name, strings.Join(errs, ", "))
+			continue
+		}
+		hostnames = append(hostnames, hostname)
+	}
+	return hostnames
+}
+
+// getNodeTargetsFromAnnotations gets the targets overriding the node addresses from the optional
+// annotation with the given key, usually the "target" annotation. All targets must be either IP
+// addresses or hostnames, otherwise an error is returned and the node addresses are used.
+func getNodeTargetsFromAnnotations(annotations map[string]string, key string) (endpoint.Targets, error) {
+	targets := getTargetsFromAnnotation(annotations, key)
+	for _, target := range targets {
+		if net.ParseIP(target) == nil && len(validation.IsDNS1123Subdomain(strings.ToLower(target))) > 0 {
+			return nil, fmt.Errorf("%q is neither an IP address nor a hostname", target)
+		}
+		if suitableType(target) != suitableType(targets[0]) {
+			return nil, fmt.Errorf("targets %s mix IP addresses and hostnames", targets)
+		}
+	}
+	return targets, nil
+}
+
+// overrideTargets returns the targets replacing the addresses of the node, taken from the
+// target annotation, the virtual node annotation or the bastion targets, if any.
+// It returns false if the node is a virtual node without valid targets, which is skipped.
+func (ns *nodeSource) overrideTargets(node *v1.Node) (endpoint.Targets, bool) {
+	targets, err := getNodeTargetsFromAnnotations(node.Annotations, targetAnnotationKey)
+	if err != nil {
+		log.Warnf("Ignoring target annotation of node %s: %v", node.Name, err)
 	}
+	if len(targets) == 0 && ns.virtualNodeKey != "" && len(node.Status.Addresses) == 0 {
+		targets, err = getNodeTargetsFromAnnotations(node.Annotations, ns.virtualNodeKey)
+		if err != nil {
+			log.Warnf("Skipping virtual node %s: %v", node.Name, err)
+			return nil, false
+		}
+		if len(targets) == 0 {
+			log.Warnf("Skipping virtual node %s without %s annotation", node.Name, ns.virtualNodeKey)
+			return nil, false
+		}
+	}
+	if ns.bastionLabel != "" && node.Labels[ns.bastionLabel] == ns.bastionValue {
+		log.Debugf("Using bastion targets %s for restricted node %s", ns.bastionTargets, node.Name)
+		targets = append(endpoint.Targets{}, ns.bastionTargets...)
+	}
+	return targets, true
+}
 
+// nodeAddress returns node's externalIP and if that's not found, node's internalIP
+// basically what k8s.io/kubernetes/pkg/util/node.GetPreferredNodeAddress does.
+// If the status lacks an externalIP, the cloud address provider is consulted first.
+// The given address types are tried in order, see NodeWithAddressTypePreference.
+// The type of the returned addresses is returned along with them.
+func (ns *nodeSource) nodeAddresses(ctx context.Context, node *v1.Node, addressTypes []v1.NodeAddressType) ([]string, v1.NodeAddressType, error) {
+	addresses := map[v1.NodeAddressType][]string{}
 	for _, addr := range node.Status.Addresses {
 		addresses[addr.Type] = append(addresses[addr.Type], addr.Address)
 	}
 
-	if len(addresses[v1.NodeExternalIP]) > 0 {
-		return addresses[v1.NodeExternalIP], nil
-	}
+	for _, addrType := range addressTypes {
+		if len(addresses[addrType]) > 0 {
+			return addresses[addrType], addrType, nil
+		}
 
-	if len(addresses[v1.NodeInternalIP]) > 0 {
-		return addresses[v1.NodeInternalIP], nil
+		if addrType == v1.NodeExternalIP && ns.cloudAddresses != nil {
+			cloudAddrs, err := ns.cloudAddresses.ExternalAddresses(ctx, node)
+			if err != nil {
+				log.Warnf("Failed to get external address of node %s from cloud metadata: %v", node.Name, err)
+			} else if len(cloudAddrs) > 0 {
+				return cloudAddrs, addrType, nil
+			}
+		}
 	}
 
-	return nil, fmt.Errorf("could not find node address for %s", node.Name)
+	return nil, "", fmt.Errorf("could not find node address for %s", node.Name)
 }
 
 // filterByAnnotations filters a list of nodes by a given annotation selector.
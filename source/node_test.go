@@ -18,12 +18,20 @@ package source
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -34,6 +42,44 @@ func TestNodeSource(t *testing.T) {
 
 	t.Run("NewNodeSource", testNodeSourceNewNodeSource)
 	t.Run("Endpoints", testNodeSourceEndpoints)
+	t.Run("Placeholder", testNodeSourcePlaceholder)
+	t.Run("CloudAddressProvider", testNodeSourceCloudAddressProvider)
+	t.Run("Terminating", testNodeSourceTerminating)
+	t.Run("Unavailable", testNodeSourceUnavailable)
+	t.Run("ContainerRuntime", testNodeSourceContainerRuntime)
+	t.Run("HashRing", testNodeSourceHashRing)
+	t.Run("LabelProviderSpecific", testNodeSourceLabelProviderSpecific)
+	t.Run("InternalDNSAlias", testNodeSourceInternalDNSAlias)
+	t.Run("SystemInfo", testNodeSourceSystemInfo)
+	t.Run("MinReadyNodes", testNodeSourceMinReadyNodes)
+	t.Run("AddressTypePreference", testNodeSourceAddressTypePreference)
+	t.Run("Zones", testNodeSourceZones)
+	t.Run("ExcludedRoles", testNodeSourceExcludedRoles)
+	t.Run("WeightLabel", testNodeSourceWeightLabel)
+	t.Run("AcceleratorRecord", testNodeSourceAcceleratorRecord)
+	t.Run("FirewallTags", testNodeSourceFirewallTags)
+	t.Run("UptimeTTL", testNodeSourceUptimeTTL)
+	t.Run("AddressTypeTemplates", testNodeSourceAddressTypeTemplates)
+	t.Run("VirtualNodeAnnotation", testNodeSourceVirtualNodeAnnotation)
+	t.Run("EndpointLabels", testNodeSourceEndpointLabels)
+	t.Run("Webhook", testNodeSourceWebhook)
+	t.Run("TemplateFunctions", testNodeSourceTemplateFunctions)
+	t.Run("HostnameAnnotation", testNodeSourceHostnameAnnotation)
+	t.Run("AnycastRecord", testNodeSourceAnycastRecord)
+	t.Run("EventHandler", testNodeSourceEventHandler)
+	t.Run("APIServerRecord", testNodeSourceAPIServerRecord)
+	t.Run("DefaultTTL", testNodeSourceDefaultTTL)
+	t.Run("SpotTTL", testNodeSourceSpotTTL)
+	t.Run("AggregateRecord", testNodeSourceAggregateRecord)
+	t.Run("ContextDeadline", testNodeSourceContextDeadline)
+	t.Run("TierRecords", testNodeSourceTierRecords)
+	t.Run("ExcludedTaints", testNodeSourceExcludedTaints)
+	t.Run("Bastion", testNodeSourceBastion)
+	t.Run("ReverseRecords", testNodeSourceReverseRecords)
+	t.Run("EgressRecord", testNodeSourceEgressRecord)
+	t.Run("ConditionRecord", testNodeSourceConditionRecord)
+	t.Run("MinReadyNodesPlaceholder", testNodeSourceMinReadyNodesPlaceholder)
+	t.Run("InvalidRecordNames", testNodeSourceInvalidRecordNames)
 }
 
 // testNodeSourceNewNodeSource tests that NewNodeService doesn't return an error.
@@ -317,6 +363,176 @@ func testNodeSourceEndpoints(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"target annotation overrides node addresses",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				targetAnnotationKey: "10.1.1.1",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"10.1.1.1"}},
+			},
+			false,
+		},
+		{
+			"comma separated target annotation overrides node addresses",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				targetAnnotationKey: "10.1.1.1, 10.1.1.2",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"10.1.1.1", "10.1.1.2"}},
+			},
+			false,
+		},
+		{
+			"hostname target annotation returns CNAME endpoint",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				targetAnnotationKey: "vip.example.org.",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "CNAME", DNSName: "node1", Targets: endpoint.Targets{"vip.example.org"}},
+			},
+			false,
+		},
+		{
+			"invalid target annotation falls back to node addresses",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				targetAnnotationKey: "10.1.1.1,not_a_host!",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			false,
+		},
+		{
+			"target annotation mixing IP addresses and hostnames falls back to node addresses",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				targetAnnotationKey: "10.1.1.1,vip.example.org",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			false,
+		},
+		{
+			"cert annotated node returns A and CERT endpoints",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				certAnnotationKey: "PKIX 12345 RSASHA256 MIIBIjAN BgkqhkiG",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "CERT", DNSName: "node1", Targets: endpoint.Targets{"PKIX 12345 RSASHA256 MIIBIjANBgkqhkiG"}},
+			},
+			false,
+		},
+		{
+			"invalid cert annotation is skipped",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				certAnnotationKey: "PKIX 70000 RSASHA256 MIIBIjANBgkqhkiG",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			false,
+		},
+		{
+			"ds annotated node returns A and DS endpoints",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				dsAnnotationKey: "60485 RSASHA1 1 2bb183af5f22588179a53b0a98631fad1a292118",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "DS", DNSName: "node1", Targets: endpoint.Targets{"60485 RSASHA1 1 2BB183AF5F22588179A53B0A98631FAD1A292118"}},
+			},
+			false,
+		},
+		{
+			"openpgpkey annotated node returns A and OPENPGPKEY endpoints",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				openPGPKeyAnnotationKey: "mJmam5ydnp+goaKjpKWmp6ipqqus ra6vsLGys7S1tre4ubq7vL2+v8DBwsPExcbHyMnKy8w=",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "OPENPGPKEY", DNSName: "node1", Targets: endpoint.Targets{"mJmam5ydnp+goaKjpKWmp6ipqqusra6vsLGys7S1tre4ubq7vL2+v8DBwsPExcbHyMnKy8w="}},
+			},
+			false,
+		},
+		{
+			"apl annotated node returns A and APL endpoints",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				aplAnnotationKey: "1:192.168.32.0/21 !192.168.38.0/28",
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "APL", DNSName: "node1", Targets: endpoint.Targets{"1:192.168.32.0/21 !1:192.168.38.0/28"}},
+			},
+			false,
+		},
+		{
+			"hinfo annotated node returns A and HINFO endpoints",
+			"",
+			"",
+			"node1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			map[string]string{},
+			map[string]string{
+				hinfoAnnotationKey: `amd64 "Ubuntu 22.04"`,
+			},
+			[]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "HINFO", DNSName: "node1", Targets: endpoint.Targets{`"amd64" "Ubuntu 22.04"`}},
+			},
+			false,
+		},
 		{
 			"node with nil Lables returns valid endpoint",
 			"",
@@ -373,3 +589,2267 @@ func testNodeSourceEndpoints(t *testing.T) {
 		})
 	}
 }
+
+// testNodeSourcePlaceholder tests that a placeholder record is emitted only when no node matches.
+func testNodeSourcePlaceholder(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		title    string
+		nodes    []*v1.Node
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title:    "no nodes without placeholder returns no endpoints",
+			expected: []*endpoint.Endpoint{},
+		},
+		{
+			title: "no nodes with placeholder returns placeholder",
+			opts:  []NodeSourceOption{NodeWithPlaceholder("nodes.example.org")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "TXT", DNSName: "nodes.example.org", Targets: endpoint.Targets{nodePlaceholderText}},
+			},
+		},
+		{
+			title: "no matching nodes with placeholder returns placeholder",
+			nodes: []*v1.Node{
+				newTestNode("node1", map[string]string{controllerAnnotationKey: "not-dns-controller"}, nil, "1.2.3.4"),
+			},
+			opts: []NodeSourceOption{NodeWithPlaceholder("nodes.example.org")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "TXT", DNSName: "nodes.example.org", Targets: endpoint.Targets{nodePlaceholderText}},
+			},
+		},
+		{
+			title: "only skipped virtual nodes with placeholder returns placeholder",
+			nodes: []*v1.Node{
+				newTestNode("virtual1", nil, map[string]string{"type": "virtual-kubelet"}),
+			},
+			opts: []NodeSourceOption{
+				NodeWithPlaceholder("nodes.example.org"),
+				NodeWithVirtualNodeAnnotation("virtual-kubelet.io/target"),
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "TXT", DNSName: "nodes.example.org", Targets: endpoint.Targets{nodePlaceholderText}},
+			},
+		},
+		{
+			title: "matching nodes with placeholder do not return placeholder",
+			nodes: []*v1.Node{newTestNode("node1", nil, nil, "1.2.3.4")},
+			opts:  []NodeSourceOption{NodeWithPlaceholder("nodes.example.org")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", tc.nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// fakeCloudAddressProvider returns fixed addresses for nodes by name.
+type fakeCloudAddressProvider struct {
+	addresses map[string][]string
+	err       error
+}
+
+func (p *fakeCloudAddressProvider) ExternalAddresses(ctx context.Context, node *v1.Node) ([]string, error) {
+	return p.addresses[node.Name], p.err
+}
+
+// testNodeSourceCloudAddressProvider tests that cloud metadata is used when the status lacks an external address.
+func testNodeSourceCloudAddressProvider(t *testing.T) {
+	t.Parallel()
+
+	internalOnly := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}},
+		},
+	}
+
+	for _, tc := range []struct {
+		title    string
+		nodes    []*v1.Node
+		provider CloudAddressProvider
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "without provider internal address is used",
+			nodes: []*v1.Node{internalOnly},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"10.0.0.1"}},
+			},
+		},
+		{
+			title:    "provider address is used when status lacks an external address",
+			nodes:    []*v1.Node{internalOnly},
+			provider: &fakeCloudAddressProvider{addresses: map[string][]string{"node1": {"1.2.3.4"}}},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title:    "status external address wins over provider",
+			nodes:    []*v1.Node{newTestNode("node1", nil, nil, "5.6.7.8")},
+			provider: &fakeCloudAddressProvider{addresses: map[string][]string{"node1": {"1.2.3.4"}}},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+		},
+		{
+			title:    "provider error falls back to internal address",
+			nodes:    []*v1.Node{internalOnly},
+			provider: &fakeCloudAddressProvider{err: fmt.Errorf("metadata unavailable")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"10.0.0.1"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			var opts []NodeSourceOption
+			if tc.provider != nil {
+				opts = append(opts, NodeWithCloudAddressProvider(tc.provider))
+			}
+			client := newTestNodeSource(t, "", tc.nodes, opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceTerminating tests that nodes being deleted are skipped when configured.
+func testNodeSourceTerminating(t *testing.T) {
+	t.Parallel()
+
+	terminating := newTestNode("node2", nil, nil, "5.6.7.8")
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	terminating.Finalizers = []string{"example.org/protect"}
+	nodes := []*v1.Node{newTestNode("node1", nil, nil, "1.2.3.4"), terminating}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "terminating nodes are published by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+		},
+		{
+			title: "terminating nodes are skipped",
+			opts:  []NodeSourceOption{NodeWithoutTerminating()},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceUnavailable tests that cordoned and not ready nodes are skipped when configured.
+func testNodeSourceUnavailable(t *testing.T) {
+	t.Parallel()
+
+	ready := newTestNode("node1", nil, nil, "1.1.1.1")
+	ready.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	unschedulable := newTestNode("node2", nil, nil, "2.2.2.2")
+	unschedulable.Spec.Unschedulable = true
+	unschedulable.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	notReady := newTestNode("node3", nil, nil, "3.3.3.3")
+	notReady.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}}
+	unknown := newTestNode("node4", nil, nil, "4.4.4.4")
+	nodes := []*v1.Node{ready, unschedulable, notReady, unknown}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "all nodes are published by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+			},
+		},
+		{
+			title: "only ready and schedulable nodes are published",
+			opts:  []NodeSourceOption{NodeWithoutUnavailable()},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceContainerRuntime tests that nodes can be filtered by container runtime.
+func testNodeSourceContainerRuntime(t *testing.T) {
+	t.Parallel()
+
+	containerd := newTestNode("node1", nil, nil, "1.2.3.4")
+	containerd.Status.NodeInfo.ContainerRuntimeVersion = "containerd://1.6.8"
+	docker := newTestNode("node2", nil, nil, "5.6.7.8")
+	docker.Status.NodeInfo.ContainerRuntimeVersion = "docker://20.10.7"
+	nodes := []*v1.Node{containerd, docker}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "all runtimes are published by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+		},
+		{
+			title: "runtime name matches",
+			opts:  []NodeSourceOption{NodeWithContainerRuntime("containerd")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title: "runtime version matches",
+			opts:  []NodeSourceOption{NodeWithContainerRuntime("docker://20.10")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+		},
+		{
+			title:    "no runtime matches",
+			opts:     []NodeSourceOption{NodeWithContainerRuntime("cri-o")},
+			expected: []*endpoint.Endpoint{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceHashRing tests that the hash ring record is ordered deterministically.
+func testNodeSourceHashRing(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, nil, "1.1.1.1"),
+		newTestNode("node2", nil, nil, "2.2.2.2", "2.2.2.3"),
+		newTestNode("node3", nil, nil, "3.3.3.3"),
+	}
+	client := newTestNodeSource(t, "", nodes, NodeWithHashRing("ring.example.org"))
+
+	// FNV-1a hashes: node1=0x0f4e2874, node2=0x124e2d2d, node3=0x114e2b9a
+	expected := []string{
+		"0f4e2874 node1 1.1.1.1",
+		"114e2b9a node3 3.3.3.3",
+		"124e2d2d node2 2.2.2.2",
+	}
+
+	for i := 0; i < 3; i++ {
+		endpoints, err := client.Endpoints(context.Background())
+		require.NoError(t, err)
+		require.Len(t, endpoints, 4)
+
+		var ring *endpoint.Endpoint
+		for _, ep := range endpoints {
+			if ep.DNSName == "ring.example.org" {
+				ring = ep
+			}
+		}
+		require.NotNil(t, ring)
+		assert.Equal(t, endpoint.RecordTypeTXT, ring.RecordType)
+		assert.Equal(t, expected, []string(ring.Targets))
+	}
+}
+
+// testNodeSourceLabelProviderSpecific tests that node labels are mapped to provider specific properties.
+func testNodeSourceLabelProviderSpecific(t *testing.T) {
+	t.Parallel()
+
+	nodeLabels := map[string]string{
+		"dns.example.org/aws-weight":         "10",
+		"dns.example.org/aws-region":         "eu-west-1",
+		"dns.example.org/cloudflare-proxied": "true",
+		"kubernetes.io/hostname":             "node1",
+	}
+	prefixes := map[string]string{
+		"dns.example.org/aws-":        "aws/",
+		"dns.example.org/cloudflare-": "external-dns.alpha.kubernetes.io/cloudflare-",
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "labels are not mapped by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title: "labels matching a prefix are mapped",
+			opts:  []NodeSourceOption{NodeWithLabelProviderSpecific(prefixes)},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: "aws/region", Value: "eu-west-1"},
+					{Name: "aws/weight", Value: "10"},
+					{Name: CloudflareProxiedKey, Value: "true"},
+				}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", []*v1.Node{newTestNode("node1", nil, nodeLabels, "1.2.3.4")}, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceInternalDNSAlias tests that InternalDNS addresses are published as CNAMEs.
+func testNodeSourceInternalDNSAlias(t *testing.T) {
+	t.Parallel()
+
+	withInternalDNS := newTestNode("node1", nil, nil, "1.2.3.4")
+	withInternalDNS.Status.Addresses = append(withInternalDNS.Status.Addresses,
+		v1.NodeAddress{Type: v1.NodeInternalDNS, Address: "ip-10-0-0-1.ec2.internal"})
+
+	for _, tc := range []struct {
+		title    string
+		nodes    []*v1.Node
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "internal DNS name is not published by default",
+			nodes: []*v1.Node{withInternalDNS},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title: "internal DNS name is published as CNAME",
+			nodes: []*v1.Node{withInternalDNS},
+			opts:  []NodeSourceOption{NodeWithInternalDNSAlias()},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "CNAME", DNSName: "ip-10-0-0-1.ec2.internal", Targets: endpoint.Targets{"node1.example.org"}},
+			},
+		},
+		{
+			title: "node without internal DNS name returns only the name record",
+			nodes: []*v1.Node{newTestNode("node1", nil, nil, "1.2.3.4")},
+			opts:  []NodeSourceOption{NodeWithInternalDNSAlias()},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "{{.Name}}.example.org", tc.nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceSystemInfo tests that the kernel version and OS image are published as TXT record.
+func testNodeSourceSystemInfo(t *testing.T) {
+	t.Parallel()
+
+	node := newTestNode("node1", nil, nil, "1.2.3.4")
+	node.Status.NodeInfo.KernelVersion = "5.15.0-1031-aws"
+	node.Status.NodeInfo.OSImage = "Ubuntu 22.04.2 LTS"
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "system info is not published by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title: "system info is published with prefix",
+			opts:  []NodeSourceOption{NodeWithSystemInfo("sysinfo.")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "TXT", DNSName: "sysinfo.node1.example.org", Targets: endpoint.Targets{"kernel-version=5.15.0-1031-aws", "os-image=Ubuntu 22.04.2 LTS"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "{{.Name}}.example.org", []*v1.Node{node}, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceMinReadyNodes tests that the last endpoints are kept while too few nodes are ready.
+func testNodeSourceMinReadyNodes(t *testing.T) {
+	t.Parallel()
+
+	ready := []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	notReady := []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}}
+
+	kubernetes := fake.NewSimpleClientset()
+	for i, ip := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		node := newTestNode(fmt.Sprintf("node%d", i+1), nil, nil, ip)
+		node.Status.Conditions = ready
+		_, err := kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	client, err := NewNodeSource(context.TODO(), kubernetes, "", "", NodeWithMinReadyNodes(2))
+	require.NoError(t, err)
+
+	allNodes := []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+		{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+		{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+	}
+
+	endpoints, err := client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, allNodes)
+
+	// dropping below the threshold keeps the previous endpoints.
+	updateTestNode(t, kubernetes, client, "node1", notReady)
+	require.NoError(t, kubernetes.CoreV1().Nodes().Delete(context.Background(), "node3", metav1.DeleteOptions{}))
+	require.Eventually(t, func() bool {
+		nodes, _ := client.(*nodeSource).nodeInformer.Lister().List(labels.Everything())
+		return len(nodes) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	endpoints, err = client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, allNodes)
+
+	// crossing the threshold again publishes the current endpoints.
+	updateTestNode(t, kubernetes, client, "node1", ready)
+
+	endpoints, err = client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+		{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+	})
+}
+
+// testNodeSourceMinReadyNodesPlaceholder tests that the placeholder does not replace the endpoints kept while too few nodes are ready.
+func testNodeSourceMinReadyNodesPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	node := newTestNode("node1", nil, nil, "1.1.1.1")
+	node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	client := newTestNodeSource(t, "", []*v1.Node{node}, NodeWithMinReadyNodes(1), NodeWithPlaceholder("nodes.example.org"))
+
+	endpoints, err := client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+	})
+
+	// losing every node keeps the previous endpoints instead of publishing the placeholder.
+	informer := client.(*nodeSource).nodeInformer
+	require.NoError(t, informer.Informer().GetIndexer().Delete(node))
+
+	endpoints, err = client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+	})
+
+	// without endpoints to keep, the placeholder is published.
+	client = newTestNodeSource(t, "", nil, NodeWithMinReadyNodes(1), NodeWithPlaceholder("nodes.example.org"))
+
+	endpoints, err = client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{RecordType: "TXT", DNSName: "nodes.example.org", Targets: endpoint.Targets{nodePlaceholderText}},
+	})
+}
+
+// updateTestNode sets the conditions of a node and waits for the node source to observe them.
+func updateTestNode(t *testing.T, kubernetes *fake.Clientset, client Source, name string, conditions []v1.NodeCondition) {
+	t.Helper()
+
+	node, err := kubernetes.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	node.Status.Conditions = conditions
+	_, err = kubernetes.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		observed, err := client.(*nodeSource).nodeInformer.Lister().Get(name)
+		return err == nil && isNodeReady(observed) == isNodeReady(node)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// testNodeSourceAddressTypePreference tests that addresses are picked in the configured type order.
+func testNodeSourceAddressTypePreference(t *testing.T) {
+	t.Parallel()
+
+	dualStack := newTestNode("node1", nil, nil, "1.2.3.4")
+	dualStack.Status.Addresses = append(dualStack.Status.Addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: "10.0.0.1"})
+	externalOnly := newTestNode("node2", nil, nil, "5.6.7.8")
+	hostnameOnly := newTestNode("node3", nil, nil)
+	hostnameOnly.Status.Addresses = []v1.NodeAddress{{Type: v1.NodeHostName, Address: "node3.cloud.example.org"}}
+	hostnameIP := newTestNode("node4", nil, nil)
+	hostnameIP.Status.Addresses = []v1.NodeAddress{{Type: v1.NodeHostName, Address: "10.0.0.4"}}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		nodes    []*v1.Node
+		expected []*endpoint.Endpoint
+		errors   bool
+	}{
+		{
+			title: "external addresses are preferred by default",
+			nodes: []*v1.Node{dualStack},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title: "internal addresses are preferred",
+			opts:  []NodeSourceOption{NodeWithAddressTypePreference([]v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalIP})},
+			nodes: []*v1.Node{dualStack},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"10.0.0.1"}},
+			},
+		},
+		{
+			title: "external addresses are preferred",
+			opts:  []NodeSourceOption{NodeWithAddressTypePreference([]v1.NodeAddressType{v1.NodeExternalIP, v1.NodeInternalIP})},
+			nodes: []*v1.Node{dualStack},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title: "node with only the non-preferred type falls through",
+			opts:  []NodeSourceOption{NodeWithAddressTypePreference([]v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalIP})},
+			nodes: []*v1.Node{externalOnly},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+		},
+		{
+			title:  "node without any configured type is an error",
+			opts:   []NodeSourceOption{NodeWithAddressTypePreference([]v1.NodeAddressType{v1.NodeInternalIP})},
+			nodes:  []*v1.Node{externalOnly},
+			errors: true,
+		},
+		{
+			title: "hostname-only node is published as a CNAME",
+			opts:  []NodeSourceOption{NodeWithAddressTypePreference([]v1.NodeAddressType{v1.NodeHostName, v1.NodeExternalIP})},
+			nodes: []*v1.Node{hostnameOnly},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "CNAME", DNSName: "node3", Targets: endpoint.Targets{"node3.cloud.example.org"}},
+			},
+		},
+		{
+			title: "hostname that is an IP is published as an A record",
+			opts:  []NodeSourceOption{NodeWithAddressTypePreference([]v1.NodeAddressType{v1.NodeHostName})},
+			nodes: []*v1.Node{hostnameIP},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"10.0.0.4"}},
+			},
+		},
+		{
+			title: "node without a hostname falls back to its external address",
+			opts:  []NodeSourceOption{NodeWithAddressTypePreference([]v1.NodeAddressType{v1.NodeHostName, v1.NodeExternalIP})},
+			nodes: []*v1.Node{externalOnly},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+		},
+		{
+			title:  "node without a hostname or internal address is an error",
+			opts:   []NodeSourceOption{NodeWithAddressTypePreference([]v1.NodeAddressType{v1.NodeHostName, v1.NodeInternalIP})},
+			nodes:  []*v1.Node{externalOnly},
+			errors: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", tc.nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			if tc.errors {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceZones tests that only nodes in allowed zones are published when configured.
+func testNodeSourceZones(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, map[string]string{v1.LabelTopologyZone: "eu-west-1a"}, "1.1.1.1"),
+		newTestNode("node2", nil, map[string]string{v1.LabelTopologyZone: "eu-west-1b"}, "2.2.2.2"),
+		newTestNode("node3", nil, map[string]string{v1.LabelFailureDomainBetaZone: "eu-west-1c"}, "3.3.3.3"),
+		newTestNode("node4", nil, nil, "4.4.4.4"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "all zones are published by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+			},
+		},
+		{
+			title: "nodes in allowed zones are published",
+			opts:  []NodeSourceOption{NodeWithZones([]string{"eu-west-1a", "eu-west-1c"})},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+			},
+		},
+		{
+			title:    "no zone matches",
+			opts:     []NodeSourceOption{NodeWithZones([]string{"us-east-1a"})},
+			expected: []*endpoint.Endpoint{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceExcludedRoles tests that nodes carrying excluded role labels are skipped.
+func testNodeSourceExcludedRoles(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, map[string]string{"node-role.kubernetes.io/control-plane": ""}, "1.1.1.1"),
+		newTestNode("node2", nil, map[string]string{"node-role.kubernetes.io/worker": ""}, "2.2.2.2"),
+		newTestNode("node3", nil, map[string]string{"node-role.kubernetes.io/master": ""}, "3.3.3.3"),
+		newTestNode("node4", map[string]string{"foo": "bar"}, nil, "4.4.4.4"),
+	}
+
+	for _, tc := range []struct {
+		title            string
+		annotationFilter string
+		opts             []NodeSourceOption
+		expected         []*endpoint.Endpoint
+	}{
+		{
+			title: "all roles are published by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+			},
+		},
+		{
+			title: "control plane and legacy master nodes are excluded",
+			opts:  []NodeSourceOption{NodeWithoutRole("control-plane")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+			},
+		},
+		{
+			title: "legacy master role excludes control plane nodes too",
+			opts:  []NodeSourceOption{NodeWithoutRole("master")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+			},
+		},
+		{
+			title: "multiple roles are excluded",
+			opts:  []NodeSourceOption{NodeWithoutRole("control-plane"), NodeWithoutRole("worker")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+			},
+		},
+		{
+			title:            "excluded roles compose with the annotation filter",
+			annotationFilter: "foo notin (bar)",
+			opts:             []NodeSourceOption{NodeWithoutRole("control-plane")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			kubernetes := fake.NewSimpleClientset()
+			for _, node := range nodes {
+				_, err := kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+
+			client, err := NewNodeSource(context.TODO(), kubernetes, tc.annotationFilter, "", tc.opts...)
+			require.NoError(t, err)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceWeightLabel tests that nodes sharing a DNS name are published as weighted records.
+func testNodeSourceWeightLabel(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, map[string]string{"dns.example.org/weight": "10"}, "1.1.1.1"),
+		newTestNode("node2", nil, map[string]string{"dns.example.org/weight": "30"}, "2.2.2.2"),
+		newTestNode("node3", nil, nil, "3.3.3.3"),
+		newTestNode("node4", nil, map[string]string{"dns.example.org/weight": "heavy"}, "4.4.4.4"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "nodes are aggregated by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4"}},
+			},
+		},
+		{
+			title: "nodes are weighted by label",
+			opts:  []NodeSourceOption{NodeWithWeightLabel("dns.example.org/weight")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"1.1.1.1"}, SetIdentifier: "node1", ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: "aws/weight", Value: "10"},
+				}},
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"2.2.2.2"}, SetIdentifier: "node2", ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: "aws/weight", Value: "30"},
+				}},
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"3.3.3.3"}, SetIdentifier: "node3", ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: "aws/weight", Value: "1"},
+				}},
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"4.4.4.4"}, SetIdentifier: "node4", ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: "aws/weight", Value: "1"},
+				}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "nodes.example.org", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceAcceleratorRecord tests that a dedicated record holds the accelerator nodes only.
+func testNodeSourceAcceleratorRecord(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, map[string]string{"nvidia.com/gpu.present": "true"}, "1.1.1.1"),
+		newTestNode("node2", nil, nil, "2.2.2.2"),
+		newTestNode("node3", nil, map[string]string{"amd.com/gpu": "1"}, "3.3.3.3"),
+		newTestNode("node4", nil, map[string]string{"nvidia.com/gpu.present": "true"}, "4.4.4.4"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "no accelerator record by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+			},
+		},
+		{
+			title: "accelerator record holds GPU nodes only",
+			opts:  []NodeSourceOption{NodeWithAcceleratorRecord("gpu.example.org", "nvidia.com/gpu.present")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+				{RecordType: "A", DNSName: "gpu.example.org", Targets: endpoint.Targets{"1.1.1.1", "4.4.4.4"}},
+			},
+		},
+		{
+			title: "any of multiple accelerator labels matches",
+			opts:  []NodeSourceOption{NodeWithAcceleratorRecord("gpu.example.org", "nvidia.com/gpu.present", "amd.com/gpu")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+				{RecordType: "A", DNSName: "gpu.example.org", Targets: endpoint.Targets{"1.1.1.1", "3.3.3.3", "4.4.4.4"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceFirewallTags tests that firewall tags are set as provider specific property.
+func testNodeSourceFirewallTags(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", map[string]string{firewallTagsAnnotationKey: " web, , internal "}, nil, "1.1.1.1"),
+		newTestNode("node2", nil, nil, "2.2.2.2"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "firewall tags are ignored by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+		{
+			title: "firewall tags are set as provider specific property",
+			opts:  []NodeSourceOption{NodeWithFirewallTags("firewall-tags")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: "firewall-tags", Value: "web,internal"},
+				}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+func testNodeSourceUptimeTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	readySince := func(node *v1.Node, since time.Time) *v1.Node {
+		node.Status.Conditions = []v1.NodeCondition{{
+			Type:               v1.NodeReady,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(since),
+		}}
+		return node
+	}
+
+	nodes := []*v1.Node{
+		readySince(newTestNode("joined", nil, nil, "1.1.1.1"), now),
+		readySince(newTestNode("warming", nil, nil, "2.2.2.2"), now.Add(-30*time.Minute)),
+		readySince(newTestNode("stable", nil, nil, "3.3.3.3"), now.Add(-2*time.Hour)),
+		newTestNode("not-ready", nil, nil, "4.4.4.4"),
+		readySince(newTestNode("annotated", map[string]string{ttlAnnotationKey: "300"}, nil, "5.5.5.5"), now),
+	}
+
+	client := newTestNodeSource(t, "", nodes,
+		NodeWithUptimeTTL(LinearNodeTTLRamp(60, 3600, time.Hour), func() time.Time { return now }))
+
+	endpoints, err := client.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "joined", Targets: endpoint.Targets{"1.1.1.1"}, RecordTTL: 60},
+		{RecordType: "A", DNSName: "warming", Targets: endpoint.Targets{"2.2.2.2"}, RecordTTL: 1830},
+		{RecordType: "A", DNSName: "stable", Targets: endpoint.Targets{"3.3.3.3"}, RecordTTL: 3600},
+		{RecordType: "A", DNSName: "not-ready", Targets: endpoint.Targets{"4.4.4.4"}, RecordTTL: 60},
+		{RecordType: "A", DNSName: "annotated", Targets: endpoint.Targets{"5.5.5.5"}, RecordTTL: 300},
+	})
+
+	t.Run("TTL increases with uptime", func(t *testing.T) {
+		t.Parallel()
+
+		clock := now
+		client := newTestNodeSource(t, "", []*v1.Node{readySince(newTestNode("node1", nil, nil, "1.1.1.1"), now)},
+			NodeWithUptimeTTL(LinearNodeTTLRamp(60, 3600, time.Hour), func() time.Time { return clock }))
+
+		var last endpoint.TTL
+		for _, uptime := range []time.Duration{0, 10 * time.Minute, 20 * time.Minute, 45 * time.Minute, time.Hour} {
+			clock = now.Add(uptime)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+			require.Len(t, endpoints, 1)
+
+			if uptime > 0 {
+				assert.Greater(t, endpoints[0].RecordTTL, last, "TTL after %s of uptime", uptime)
+			}
+			last = endpoints[0].RecordTTL
+		}
+		assert.Equal(t, endpoint.TTL(3600), last)
+	})
+}
+
+func testNodeSourceAddressTypeTemplates(t *testing.T) {
+	t.Parallel()
+
+	dualHomed := newTestNode("node1", nil, nil, "1.1.1.1")
+	dualHomed.Status.Addresses = append(dualHomed.Status.Addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: "10.0.0.1"})
+	externalOnly := newTestNode("node2", nil, nil, "2.2.2.2")
+	annotated := newTestNode("node3", map[string]string{targetAnnotationKey: "3.3.3.3"}, nil, "4.4.4.4")
+	nodes := []*v1.Node{dualHomed, externalOnly, annotated}
+
+	for _, tc := range []struct {
+		title    string
+		internal string
+		external string
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title:    "both templates publish internal and external records",
+			internal: "{{.Name}}.internal.example.org",
+			external: "{{.Name}}.example.org",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.internal.example.org", Targets: endpoint.Targets{"10.0.0.1"}},
+				{RecordType: "A", DNSName: "node1.example.org", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2.example.org", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3.internal.example.org", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node3.example.org", Targets: endpoint.Targets{"3.3.3.3"}},
+			},
+		},
+		{
+			title:    "only the internal template publishes internal records",
+			internal: "{{.Name}}.internal.example.org",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.internal.example.org", Targets: endpoint.Targets{"10.0.0.1"}},
+				{RecordType: "A", DNSName: "node3.internal.example.org", Targets: endpoint.Targets{"3.3.3.3"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, NodeWithAddressTypeTemplates(tc.internal, tc.external))
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+
+	t.Run("external addresses from the cloud address provider", func(t *testing.T) {
+		t.Parallel()
+
+		internalOnly := newTestNode("node4", nil, nil)
+		internalOnly.Status.Addresses = []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.4"}}
+		provider := &fakeCloudAddressProvider{addresses: map[string][]string{"node4": {"4.4.4.4"}}}
+
+		client := newTestNodeSource(t, "", []*v1.Node{internalOnly},
+			NodeWithAddressTypeTemplates("{{.Name}}.internal.example.org", "{{.Name}}.example.org"),
+			NodeWithCloudAddressProvider(provider))
+
+		endpoints, err := client.Endpoints(context.Background())
+		require.NoError(t, err)
+
+		validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+			{RecordType: "A", DNSName: "node4.internal.example.org", Targets: endpoint.Targets{"10.0.0.4"}},
+			{RecordType: "A", DNSName: "node4.example.org", Targets: endpoint.Targets{"4.4.4.4"}},
+		})
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewNodeSource(context.TODO(), fake.NewSimpleClientset(), "", "", NodeWithAddressTypeTemplates("{{.Name", ""))
+		require.Error(t, err)
+	})
+}
+
+func testNodeSourceVirtualNodeAnnotation(t *testing.T) {
+	t.Parallel()
+
+	const virtualNodeKey = "virtual-kubelet.io/target"
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, nil, "1.1.1.1"),
+		newTestNode("virtual1", map[string]string{virtualNodeKey: "10.0.0.1,10.0.0.2"}, map[string]string{"type": "virtual-kubelet"}),
+		newTestNode("virtual2", map[string]string{virtualNodeKey: "aci.example.org"}, map[string]string{"type": "virtual-kubelet"}),
+		newTestNode("virtual3", nil, map[string]string{"type": "virtual-kubelet"}),
+		newTestNode("annotated", map[string]string{virtualNodeKey: "10.0.0.9"}, nil, "3.3.3.3"),
+	}
+
+	client := newTestNodeSource(t, "", nodes, NodeWithVirtualNodeAnnotation(virtualNodeKey))
+
+	endpoints, err := client.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+		{RecordType: "A", DNSName: "virtual1", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.2"}},
+		{RecordType: "CNAME", DNSName: "virtual2", Targets: endpoint.Targets{"aci.example.org"}},
+		{RecordType: "A", DNSName: "annotated", Targets: endpoint.Targets{"3.3.3.3"}},
+	})
+}
+
+func testNodeSourceEndpointLabels(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, map[string]string{
+			"dns.example.org/provider": "aws",
+			"dns.example.org/zone":     "public",
+			"team":                     "infra",
+			"tier":                     "frontend",
+			endpoint.OwnerLabelKey:     "someone-else",
+		}, "1.1.1.1"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		prefixes []string
+		keys     []string
+		expected endpoint.Labels
+	}{
+		{
+			title:    "labels matching a prefix are copied",
+			prefixes: []string{"dns.example.org/"},
+			expected: endpoint.Labels{"dns.example.org/provider": "aws", "dns.example.org/zone": "public"},
+		},
+		{
+			title:    "labels from the key list are copied",
+			keys:     []string{"team", "missing"},
+			expected: endpoint.Labels{"team": "infra"},
+		},
+		{
+			title:    "reserved labels are not copied",
+			prefixes: []string{"t"},
+			keys:     []string{endpoint.OwnerLabelKey},
+			expected: endpoint.Labels{"team": "infra", "tier": "frontend"},
+		},
+		{
+			title:    "labels are empty without matching node labels",
+			prefixes: []string{"other.example.org/"},
+			keys:     []string{"missing"},
+			expected: endpoint.Labels{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, NodeWithEndpointLabels(tc.prefixes, tc.keys))
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+			require.Len(t, endpoints, 1)
+
+			require.NotNil(t, endpoints[0].Labels)
+			assert.Equal(t, tc.expected, endpoints[0].Labels)
+		})
+	}
+}
+
+func testNodeSourceWebhook(t *testing.T) {
+	t.Parallel()
+
+	var requests, failing int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) != 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var review nodeWebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(nodeWebhookResponse{Allowed: review.Labels["policy"] == "approved"})
+	}))
+	defer server.Close()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, map[string]string{"policy": "approved"}, "1.1.1.1"),
+		newTestNode("node2", nil, map[string]string{"policy": "denied"}, "2.2.2.2"),
+	}
+
+	now := time.Now()
+	client := newTestNodeSource(t, "", nodes, NodeWithWebhook(server.URL, time.Second, time.Minute))
+	client.(*nodeSource).webhook.now = func() time.Time { return now }
+
+	expected := []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+	}
+
+	// the webhook approves node1 and denies node2
+	endpoints, err := client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, expected)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+
+	// decisions are cached
+	endpoints, err = client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, expected)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+
+	// expired decisions are kept if the webhook fails
+	atomic.StoreInt32(&failing, 1)
+	now = now.Add(2 * time.Minute)
+	endpoints, err = client.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, expected)
+	assert.EqualValues(t, 4, atomic.LoadInt32(&requests))
+
+	t.Run("unknown nodes are skipped if the webhook times out", func(t *testing.T) {
+		t.Parallel()
+
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(500 * time.Millisecond)
+			json.NewEncoder(w).Encode(nodeWebhookResponse{Allowed: true})
+		}))
+		defer slow.Close()
+
+		client := newTestNodeSource(t, "", nodes, NodeWithWebhook(slow.URL, 50*time.Millisecond, time.Minute))
+
+		endpoints, err := client.Endpoints(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, endpoints)
+	})
+
+	t.Run("a slow node doesn't block other nodes", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		blocking := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var review nodeWebhookRequest
+			_ = json.NewDecoder(r.Body).Decode(&review)
+			if review.Name == "node1" {
+				<-release
+			}
+			json.NewEncoder(w).Encode(nodeWebhookResponse{Allowed: true})
+		}))
+		defer blocking.Close()
+		defer close(release)
+
+		webhook := newNodeWebhook(blocking.URL, time.Minute, time.Minute)
+		go webhook.allowed(context.Background(), nodes[0])
+
+		done := make(chan bool)
+		go func() { done <- webhook.allowed(context.Background(), nodes[1]) }()
+		select {
+		case allowed := <-done:
+			assert.True(t, allowed)
+		case <-time.After(5 * time.Second):
+			t.Fatal("lookup of node2 blocked by node1")
+		}
+	})
+
+	t.Run("decisions of deleted nodes are pruned", func(t *testing.T) {
+		t.Parallel()
+
+		approving := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(nodeWebhookResponse{Allowed: true})
+		}))
+		defer approving.Close()
+
+		client := newTestNodeSource(t, "", nodes, NodeWithWebhook(approving.URL, time.Second, time.Minute))
+		ns := client.(*nodeSource)
+
+		_, err := client.Endpoints(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, ns.webhook.cache, 2)
+
+		require.NoError(t, ns.nodeInformer.Informer().GetIndexer().Delete(nodes[1]))
+		_, err = client.Endpoints(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, ns.webhook.cache, 1)
+		assert.Contains(t, ns.webhook.cache, "node1")
+	})
+}
+
+func testNodeSourceTemplateFunctions(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("IP-10-0-0-1.EC2.Internal", nil, nil, "1.1.1.1"),
+	}
+
+	for _, tc := range []struct {
+		title        string
+		fqdnTemplate string
+		expected     string
+	}{
+		{
+			title:        "lower and trimSuffix",
+			fqdnTemplate: `{{ .Name | lower | trimSuffix ".ec2.internal" }}.example.org`,
+			expected:     "ip-10-0-0-1.example.org",
+		},
+		{
+			title:        "upper",
+			fqdnTemplate: `{{ .Name | upper }}.example.org`,
+			expected:     "IP-10-0-0-1.EC2.INTERNAL.example.org",
+		},
+		{
+			title:        "replace",
+			fqdnTemplate: `{{ .Name | lower | replace "." "-" }}.example.org`,
+			expected:     "ip-10-0-0-1-ec2-internal.example.org",
+		},
+		{
+			title:        "trimPrefix",
+			fqdnTemplate: `{{ trimPrefix .Name "IP-" }}.example.org`,
+			expected:     "10-0-0-1.EC2.Internal.example.org",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, tc.fqdnTemplate, nodes)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: tc.expected, Targets: endpoint.Targets{"1.1.1.1"}},
+			})
+		})
+	}
+}
+
+func testNodeSourceHostnameAnnotation(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		title    string
+		nodes    []*v1.Node
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "single hostname overrides the template",
+			nodes: []*v1.Node{
+				newTestNode("node1", map[string]string{hostnameAnnotationKey: "custom.example.org"}, nil, "1.1.1.1"),
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "custom.example.org", Targets: endpoint.Targets{"1.1.1.1"}},
+			},
+		},
+		{
+			title: "multiple hostnames produce an endpoint each",
+			nodes: []*v1.Node{
+				newTestNode("node1", map[string]string{
+					hostnameAnnotationKey: "one.example.org, two.example.org.",
+					ttlAnnotationKey:      "60",
+				}, nil, "1.1.1.1", "2.2.2.2"),
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "one.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}, RecordTTL: 60},
+				{RecordType: "A", DNSName: "two.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}, RecordTTL: 60},
+			},
+		},
+		{
+			title: "annotated and non-annotated nodes coexist",
+			nodes: []*v1.Node{
+				newTestNode("node1", map[string]string{hostnameAnnotationKey: "custom.example.org"}, nil, "1.1.1.1"),
+				newTestNode("node2", nil, nil, "2.2.2.2"),
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "custom.example.org", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2.nodes.example.org", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+		{
+			title: "invalid hostnames are ignored",
+			nodes: []*v1.Node{
+				newTestNode("node1", map[string]string{hostnameAnnotationKey: "in_valid.example.org,valid.example.org"}, nil, "1.1.1.1"),
+				newTestNode("node2", map[string]string{hostnameAnnotationKey: "-invalid"}, nil, "2.2.2.2"),
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "valid.example.org", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2.nodes.example.org", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "{{.Name}}.nodes.example.org", tc.nodes)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+func testNodeSourceAnycastRecord(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", map[string]string{anycastAnnotationKey: "192.0.2.1,192.0.2.2"}, map[string]string{v1.LabelTopologyRegion: "eu-west-1"}, "1.1.1.1"),
+		newTestNode("node2", map[string]string{anycastAnnotationKey: "192.0.2.2, 2001:db8::1"}, map[string]string{v1.LabelTopologyRegion: "us-east-1"}, "2.2.2.2"),
+		newTestNode("node3", map[string]string{anycastAnnotationKey: "2001:db8::1,not-an-ip"}, map[string]string{v1.LabelTopologyRegion: "ap-south-1"}, "3.3.3.3"),
+		newTestNode("node4", nil, map[string]string{v1.LabelTopologyRegion: "eu-west-1"}, "4.4.4.4"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "anycast addresses are ignored by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+			},
+		},
+		{
+			title: "anycast addresses shared across regions are published once",
+			opts:  []NodeSourceOption{NodeWithAnycastRecord("anycast.example.org")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+				{RecordType: "A", DNSName: "anycast.example.org", Targets: endpoint.Targets{"192.0.2.1", "192.0.2.2"}},
+				{RecordType: "AAAA", DNSName: "anycast.example.org", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+func testNodeSourceEventHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubernetes := fake.NewSimpleClientset()
+	client, err := NewNodeSource(ctx, kubernetes, "", "")
+	require.NoError(t, err)
+
+	events := make(chan struct{}, 10)
+	client.AddEventHandler(ctx, func() { events <- struct{}{} })
+
+	expectEvent := func(action string) {
+		t.Helper()
+		select {
+		case <-events:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected event handler to be called after node %s", action)
+		}
+	}
+
+	node := newTestNode("node1", nil, nil, "1.1.1.1")
+	_, err = kubernetes.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
+	require.NoError(t, err)
+	expectEvent("creation")
+
+	node.Labels = map[string]string{"team": "infra"}
+	_, err = kubernetes.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	expectEvent("update")
+
+	require.Eventually(t, func() bool {
+		endpoints, err := client.Endpoints(ctx)
+		return err == nil && len(endpoints) == 1
+	}, 5*time.Second, 10*time.Millisecond, "endpoints must be read from the informer cache")
+
+	require.NoError(t, kubernetes.CoreV1().Nodes().Delete(ctx, node.Name, metav1.DeleteOptions{}))
+	expectEvent("deletion")
+}
+
+// testNodeSourceAPIServerRecord tests that the node source publishes a record for the addresses
+// of the default/kubernetes service.
+func testNodeSourceAPIServerRecord(t *testing.T) {
+	t.Parallel()
+
+	apiServer := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kubernetes"},
+		Subsets: []v1.EndpointSubset{
+			{Addresses: []v1.EndpointAddress{{IP: "10.0.0.2"}, {IP: "10.0.0.1"}}},
+			{Addresses: []v1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "fd00::1"}}},
+		},
+	}
+	nodes := []*v1.Node{newTestNode("node1", nil, nil, "1.1.1.1")}
+
+	for _, tc := range []struct {
+		title     string
+		apiServer *v1.Endpoints
+		nodes     []*v1.Node
+		opts      []NodeSourceOption
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			title:     "api server addresses are ignored by default",
+			apiServer: apiServer,
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+			},
+		},
+		{
+			title:     "api server addresses are published",
+			apiServer: apiServer,
+			opts:      []NodeSourceOption{NodeWithAPIServerRecord("api.example.org")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "api.example.org", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.2"}},
+				{RecordType: "AAAA", DNSName: "api.example.org", Targets: endpoint.Targets{"fd00::1"}},
+			},
+		},
+		{
+			title:     "api server record does not suppress the placeholder",
+			apiServer: apiServer,
+			nodes: []*v1.Node{
+				newTestNode("node1", map[string]string{controllerAnnotationKey: "not-dns-controller"}, nil, "1.1.1.1"),
+			},
+			opts: []NodeSourceOption{NodeWithAPIServerRecord("api.example.org"), NodeWithPlaceholder("nodes.example.org")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "api.example.org", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.2"}},
+				{RecordType: "AAAA", DNSName: "api.example.org", Targets: endpoint.Targets{"fd00::1"}},
+				{RecordType: "TXT", DNSName: "nodes.example.org", Targets: endpoint.Targets{nodePlaceholderText}},
+			},
+		},
+		{
+			title: "missing api server endpoints are skipped",
+			opts:  []NodeSourceOption{NodeWithAPIServerRecord("api.example.org")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			kubernetes := fake.NewSimpleClientset()
+			if tc.nodes == nil {
+				tc.nodes = nodes
+			}
+			for _, node := range tc.nodes {
+				_, err := kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			if tc.apiServer != nil {
+				_, err := kubernetes.CoreV1().Endpoints("default").Create(context.Background(), tc.apiServer, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+
+			client, err := NewNodeSource(context.TODO(), kubernetes, "", "", tc.opts...)
+			require.NoError(t, err)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceDefaultTTL tests that the default TTL applies to nodes without a valid TTL annotation.
+func testNodeSourceDefaultTTL(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("plain", nil, nil, "1.1.1.1"),
+		newTestNode("annotated", map[string]string{ttlAnnotationKey: "10"}, nil, "2.2.2.2"),
+		newTestNode("invalid", map[string]string{ttlAnnotationKey: "foo"}, nil, "3.3.3.3"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "TTL stays unconfigured without a default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "plain", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "annotated", Targets: endpoint.Targets{"2.2.2.2"}, RecordTTL: 10},
+				{RecordType: "A", DNSName: "invalid", Targets: endpoint.Targets{"3.3.3.3"}},
+			},
+		},
+		{
+			title: "default TTL applies to nodes without a valid annotation",
+			opts:  []NodeSourceOption{NodeWithDefaultTTL(300)},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "plain", Targets: endpoint.Targets{"1.1.1.1"}, RecordTTL: 300},
+				{RecordType: "A", DNSName: "annotated", Targets: endpoint.Targets{"2.2.2.2"}, RecordTTL: 10},
+				{RecordType: "A", DNSName: "invalid", Targets: endpoint.Targets{"3.3.3.3"}, RecordTTL: 300},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceSpotTTL tests that spot nodes get the shorter TTL and on-demand nodes the default one.
+func testNodeSourceSpotTTL(t *testing.T) {
+	t.Parallel()
+
+	const capacityType = "eks.amazonaws.com/capacityType"
+	nodes := []*v1.Node{
+		newTestNode("spot", nil, map[string]string{capacityType: "SPOT"}, "1.1.1.1"),
+		newTestNode("on-demand", nil, map[string]string{capacityType: "ON_DEMAND"}, "2.2.2.2"),
+		newTestNode("unlabeled", nil, nil, "3.3.3.3"),
+		newTestNode("annotated-spot", map[string]string{ttlAnnotationKey: "120"}, map[string]string{capacityType: "SPOT"}, "4.4.4.4"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "spot nodes are not detected by default",
+			opts:  []NodeSourceOption{NodeWithDefaultTTL(300)},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "spot", Targets: endpoint.Targets{"1.1.1.1"}, RecordTTL: 300},
+				{RecordType: "A", DNSName: "on-demand", Targets: endpoint.Targets{"2.2.2.2"}, RecordTTL: 300},
+				{RecordType: "A", DNSName: "unlabeled", Targets: endpoint.Targets{"3.3.3.3"}, RecordTTL: 300},
+				{RecordType: "A", DNSName: "annotated-spot", Targets: endpoint.Targets{"4.4.4.4"}, RecordTTL: 120},
+			},
+		},
+		{
+			title: "spot nodes get the shorter TTL",
+			opts:  []NodeSourceOption{NodeWithDefaultTTL(300), NodeWithSpotTTL(capacityType, "SPOT", 30)},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "spot", Targets: endpoint.Targets{"1.1.1.1"}, RecordTTL: 30},
+				{RecordType: "A", DNSName: "on-demand", Targets: endpoint.Targets{"2.2.2.2"}, RecordTTL: 300},
+				{RecordType: "A", DNSName: "unlabeled", Targets: endpoint.Targets{"3.3.3.3"}, RecordTTL: 300},
+				{RecordType: "A", DNSName: "annotated-spot", Targets: endpoint.Targets{"4.4.4.4"}, RecordTTL: 120},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceAggregateRecord tests that the node source publishes a record with the addresses of all matching nodes.
+func testNodeSourceAggregateRecord(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, nil, "3.3.3.3"),
+		newTestNode("node2", nil, nil, "1.1.1.1", "2001:db8::1"),
+		newTestNode("node3", nil, nil, "2.2.2.2"),
+	}
+	reversed := []*v1.Node{nodes[2], nodes[1], nodes[0]}
+
+	for _, tc := range []struct {
+		title    string
+		nodes    []*v1.Node
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "aggregate record is published besides the node records",
+			nodes: nodes,
+			opts:  []NodeSourceOption{NodeWithAggregateRecord("nodes.example.org", false)},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"1.1.1.1", "2001:db8::1"}},
+				{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"2.2.2.2"}},
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2", "3.3.3.3"}},
+				{RecordType: "AAAA", DNSName: "nodes.example.org", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			title: "aggregate record replaces the node records",
+			nodes: nodes,
+			opts:  []NodeSourceOption{NodeWithAggregateRecord("nodes.example.org", true)},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2", "3.3.3.3"}},
+				{RecordType: "AAAA", DNSName: "nodes.example.org", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			title: "aggregate targets do not depend on the node order",
+			nodes: reversed,
+			opts:  []NodeSourceOption{NodeWithAggregateRecord("nodes.example.org", true)},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2", "3.3.3.3"}},
+				{RecordType: "AAAA", DNSName: "nodes.example.org", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			title:    "no aggregate record is published without matching nodes",
+			opts:     []NodeSourceOption{NodeWithAggregateRecord("nodes.example.org", false)},
+			expected: []*endpoint.Endpoint{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", tc.nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceContextDeadline tests that the node source returns promptly once the context expired.
+func testNodeSourceContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	var reviews int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reviews, 1)
+		time.Sleep(time.Second)
+		fmt.Fprint(w, `{"allowed": true}`)
+	}))
+	defer webhook.Close()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", nil, nil, "1.1.1.1"),
+		newTestNode("node2", nil, nil, "2.2.2.2"),
+	}
+	client := newTestNodeSource(t, "", nodes, NodeWithWebhook(webhook.URL, time.Minute, 0))
+
+	t.Run("expired deadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		start := time.Now()
+		_, err := client.Endpoints(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Zero(t, atomic.LoadInt32(&reviews), "no webhook call expected")
+	})
+
+	t.Run("deadline expiring during the sync", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := client.Endpoints(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+}
+
+// testNodeSourceTierRecords tests that the node source publishes a record per tier of nodes.
+func testNodeSourceTierRecords(t *testing.T) {
+	t.Parallel()
+
+	withTaints := func(node *v1.Node, keys ...string) *v1.Node {
+		for _, key := range keys {
+			node.Spec.Taints = append(node.Spec.Taints, v1.Taint{Key: key, Effect: v1.TaintEffectNoSchedule})
+		}
+		return node
+	}
+
+	nodes := []*v1.Node{
+		withTaints(newTestNode("node1", nil, map[string]string{"tier": "premium"}, "1.1.1.1"), "dedicated"),
+		withTaints(newTestNode("node2", nil, map[string]string{"tier": "premium"}, "2.2.2.2"), "dedicated"),
+		newTestNode("node3", nil, map[string]string{"tier": "premium"}, "3.3.3.3"),
+		newTestNode("node4", nil, map[string]string{"tier": "standard"}, "4.4.4.4"),
+		withTaints(newTestNode("node5", nil, nil, "5.5.5.5"), "spot"),
+		newTestNode("node6", nil, nil, "6.6.6.6"),
+	}
+	nodeEndpoints := []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.1.1.1"}},
+		{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"2.2.2.2"}},
+		{RecordType: "A", DNSName: "node3", Targets: endpoint.Targets{"3.3.3.3"}},
+		{RecordType: "A", DNSName: "node4", Targets: endpoint.Targets{"4.4.4.4"}},
+		{RecordType: "A", DNSName: "node5", Targets: endpoint.Targets{"5.5.5.5"}},
+		{RecordType: "A", DNSName: "node6", Targets: endpoint.Targets{"6.6.6.6"}},
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title:    "tiers are not published by default",
+			expected: nodeEndpoints,
+		},
+		{
+			title: "nodes are aggregated into their first matching tier",
+			opts: []NodeSourceOption{NodeWithTierRecords(
+				NodeTier{DNSName: "gold.example.org", Labels: map[string]string{"tier": "premium"}, Taints: []string{"dedicated"}},
+				NodeTier{DNSName: "silver.example.org", Labels: map[string]string{"tier": "premium"}},
+				NodeTier{DNSName: "bronze.example.org", Taints: []string{"spot"}},
+				NodeTier{DNSName: "copper.example.org", Labels: map[string]string{"tier": "basic"}},
+			)},
+			expected: append([]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "gold.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}},
+				{RecordType: "A", DNSName: "silver.example.org", Targets: endpoint.Targets{"3.3.3.3"}},
+				{RecordType: "A", DNSName: "bronze.example.org", Targets: endpoint.Targets{"5.5.5.5"}},
+			}, nodeEndpoints...),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceExcludedTaints tests that nodes carrying excluded taints are skipped.
+func testNodeSourceExcludedTaints(t *testing.T) {
+	t.Parallel()
+
+	withTaint := func(node *v1.Node, key string, effect v1.TaintEffect) *v1.Node {
+		node.Spec.Taints = append(node.Spec.Taints, v1.Taint{Key: key, Value: "true", Effect: effect})
+		return node
+	}
+
+	nodes := []*v1.Node{
+		withTaint(newTestNode("maintenance", nil, nil, "1.1.1.1"), "maintenance", v1.TaintEffectNoSchedule),
+		withTaint(newTestNode("gpu-noschedule", nil, nil, "2.2.2.2"), "nvidia.com/gpu", v1.TaintEffectNoSchedule),
+		withTaint(newTestNode("gpu-prefer", nil, nil, "3.3.3.3"), "nvidia.com/gpu", v1.TaintEffectPreferNoSchedule),
+		withTaint(newTestNode("other", nil, nil, "4.4.4.4"), "dedicated", v1.TaintEffectNoSchedule),
+		newTestNode("untainted", nil, nil, "5.5.5.5"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []string
+	}{
+		{
+			title:    "taints are not filtered by default",
+			expected: []string{"maintenance", "gpu-noschedule", "gpu-prefer", "other", "untainted"},
+		},
+		{
+			title:    "taint without effect excludes any effect",
+			opts:     []NodeSourceOption{NodeWithoutTaint("nvidia.com/gpu", "")},
+			expected: []string{"maintenance", "other", "untainted"},
+		},
+		{
+			title:    "taint with effect excludes only that effect",
+			opts:     []NodeSourceOption{NodeWithoutTaint("nvidia.com/gpu", v1.TaintEffectNoSchedule)},
+			expected: []string{"maintenance", "gpu-prefer", "other", "untainted"},
+		},
+		{
+			title:    "several taints are excluded",
+			opts:     []NodeSourceOption{NodeWithoutTaint("maintenance", v1.TaintEffectNoSchedule), NodeWithoutTaint("nvidia.com/gpu", v1.TaintEffectPreferNoSchedule)},
+			expected: []string{"gpu-noschedule", "other", "untainted"},
+		},
+		{
+			title:    "taint with another effect is not excluded",
+			opts:     []NodeSourceOption{NodeWithoutTaint("maintenance", v1.TaintEffectNoExecute)},
+			expected: []string{"maintenance", "gpu-noschedule", "gpu-prefer", "other", "untainted"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			names := []string{}
+			for _, ep := range endpoints {
+				names = append(names, ep.DNSName)
+			}
+			assert.ElementsMatch(t, tc.expected, names)
+		})
+	}
+}
+
+// testNodeSourceBastion tests that the records of restricted nodes point at the bastion.
+func testNodeSourceBastion(t *testing.T) {
+	t.Parallel()
+
+	const pool = "example.org/pool"
+	nodes := []*v1.Node{
+		newTestNode("restricted1", nil, map[string]string{pool: "restricted"}, "10.0.0.1"),
+		newTestNode("restricted2", map[string]string{targetAnnotationKey: "10.0.0.20"}, map[string]string{pool: "restricted"}, "10.0.0.2"),
+		newTestNode("public", nil, map[string]string{pool: "public"}, "1.1.1.1"),
+		newTestNode("unlabeled", nil, nil, "2.2.2.2"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "node addresses are used by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "restricted1", Targets: endpoint.Targets{"10.0.0.1"}},
+				{RecordType: "A", DNSName: "restricted2", Targets: endpoint.Targets{"10.0.0.20"}},
+				{RecordType: "A", DNSName: "public", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "unlabeled", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+		{
+			title: "restricted nodes point at the bastion addresses",
+			opts:  []NodeSourceOption{NodeWithBastion(pool, "restricted", "192.0.2.1", "192.0.2.2")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "restricted1", Targets: endpoint.Targets{"192.0.2.1", "192.0.2.2"}},
+				{RecordType: "A", DNSName: "restricted2", Targets: endpoint.Targets{"192.0.2.1", "192.0.2.2"}},
+				{RecordType: "A", DNSName: "public", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "unlabeled", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+		{
+			title: "restricted nodes point at the bastion hostname",
+			opts:  []NodeSourceOption{NodeWithBastion(pool, "restricted", "bastion.example.org")},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "CNAME", DNSName: "restricted1", Targets: endpoint.Targets{"bastion.example.org"}},
+				{RecordType: "CNAME", DNSName: "restricted2", Targets: endpoint.Targets{"bastion.example.org"}},
+				{RecordType: "A", DNSName: "public", Targets: endpoint.Targets{"1.1.1.1"}},
+				{RecordType: "A", DNSName: "unlabeled", Targets: endpoint.Targets{"2.2.2.2"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceReverseRecords tests that the node source publishes PTR records for the node addresses.
+func testNodeSourceReverseRecords(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*v1.Node{
+		newTestNode("node1", map[string]string{ttlAnnotationKey: "300"}, nil, "192.0.2.10", "2001:db8::1"),
+		newTestNode("node2", map[string]string{targetAnnotationKey: "lb.example.org"}, nil, "192.0.2.20"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "PTR records are not published by default",
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.example.org", Targets: endpoint.Targets{"192.0.2.10", "2001:db8::1"}, RecordTTL: 300},
+				{RecordType: "CNAME", DNSName: "node2.example.org", Targets: endpoint.Targets{"lb.example.org"}},
+			},
+		},
+		{
+			title: "PTR records point at the node records",
+			opts:  []NodeSourceOption{NodeWithReverseRecords()},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.example.org", Targets: endpoint.Targets{"192.0.2.10", "2001:db8::1"}, RecordTTL: 300},
+				{RecordType: "PTR", DNSName: "10.2.0.192.in-addr.arpa", Targets: endpoint.Targets{"node1.example.org"}, RecordTTL: 300},
+				{RecordType: "PTR", DNSName: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa", Targets: endpoint.Targets{"node1.example.org"}, RecordTTL: 300},
+				{RecordType: "CNAME", DNSName: "node2.example.org", Targets: endpoint.Targets{"lb.example.org"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "{{.Name}}.example.org", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceEgressRecord tests that the node source publishes records for the egress IPs of the nodes.
+func testNodeSourceEgressRecord(t *testing.T) {
+	t.Parallel()
+
+	const egressKey = "example.org/egress-ips"
+	nodes := []*v1.Node{
+		newTestNode("annotated", map[string]string{egressKey: "203.0.113.1, 203.0.113.2,2001:db8::1", ttlAnnotationKey: "60"}, nil, "1.1.1.1"),
+		newTestNode("labeled", nil, map[string]string{egressKey: "203.0.113.3"}, "2.2.2.2"),
+		newTestNode("invalid", map[string]string{egressKey: "not-an-ip"}, nil, "3.3.3.3"),
+		newTestNode("plain", nil, nil, "4.4.4.4"),
+		newTestNode(longNodeName, map[string]string{egressKey: "203.0.113.5"}, nil, "5.5.5.5"),
+	}
+	nodeEndpoints := []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "annotated", Targets: endpoint.Targets{"1.1.1.1"}, RecordTTL: 60},
+		{RecordType: "A", DNSName: "labeled", Targets: endpoint.Targets{"2.2.2.2"}},
+		{RecordType: "A", DNSName: "invalid", Targets: endpoint.Targets{"3.3.3.3"}},
+		{RecordType: "A", DNSName: "plain", Targets: endpoint.Targets{"4.4.4.4"}},
+		{RecordType: "A", DNSName: longNodeName, Targets: endpoint.Targets{"5.5.5.5"}},
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title:    "egress IPs are ignored by default",
+			expected: nodeEndpoints,
+		},
+		{
+			title: "egress records point at the egress IPs",
+			opts:  []NodeSourceOption{NodeWithEgressRecord(egressKey, "egress-")},
+			expected: append([]*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "egress-annotated", Targets: endpoint.Targets{"203.0.113.1", "203.0.113.2"}, RecordTTL: 60},
+				{RecordType: "AAAA", DNSName: "egress-annotated", Targets: endpoint.Targets{"2001:db8::1"}, RecordTTL: 60},
+				{RecordType: "A", DNSName: "egress-labeled", Targets: endpoint.Targets{"203.0.113.3"}},
+			}, nodeEndpoints...),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceConditionRecord tests that the node source publishes the state of a node condition as TXT record.
+func testNodeSourceConditionRecord(t *testing.T) {
+	t.Parallel()
+
+	const kernelDeadlock v1.NodeConditionType = "KernelDeadlock"
+	deadlocked := newTestNode("deadlocked", map[string]string{ttlAnnotationKey: "60"}, nil, "1.1.1.1")
+	deadlocked.Status.Conditions = []v1.NodeCondition{
+		{Type: v1.NodeReady, Status: v1.ConditionTrue},
+		{Type: kernelDeadlock, Status: v1.ConditionTrue, Reason: "DockerHung"},
+	}
+	healthy := newTestNode("healthy", nil, nil, "2.2.2.2")
+	healthy.Status.Conditions = []v1.NodeCondition{
+		{Type: kernelDeadlock, Status: v1.ConditionFalse},
+	}
+	unmonitored := newTestNode("unmonitored", nil, nil, "3.3.3.3")
+	nodes := []*v1.Node{deadlocked, healthy, unmonitored}
+	nodeEndpoints := []*endpoint.Endpoint{
+		{RecordType: "A", DNSName: "deadlocked", Targets: endpoint.Targets{"1.1.1.1"}, RecordTTL: 60},
+		{RecordType: "A", DNSName: "healthy", Targets: endpoint.Targets{"2.2.2.2"}},
+		{RecordType: "A", DNSName: "unmonitored", Targets: endpoint.Targets{"3.3.3.3"}},
+	}
+
+	for _, tc := range []struct {
+		title    string
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title:    "conditions are not published by default",
+			expected: nodeEndpoints,
+		},
+		{
+			title: "condition records hold the condition state",
+			opts:  []NodeSourceOption{NodeWithConditionRecord(kernelDeadlock, "kernel-deadlock.")},
+			expected: append([]*endpoint.Endpoint{
+				{RecordType: "TXT", DNSName: "kernel-deadlock.deadlocked", Targets: endpoint.Targets{"status=True", "reason=DockerHung"}, RecordTTL: 60},
+				{RecordType: "TXT", DNSName: "kernel-deadlock.healthy", Targets: endpoint.Targets{"status=False"}},
+			}, nodeEndpoints...),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceInvalidRecordNames tests that records with names exceeding the DNS label length limit are skipped.
+func testNodeSourceInvalidRecordNames(t *testing.T) {
+	t.Parallel()
+
+	node := newTestNode(longNodeName, nil, nil, "1.1.1.1")
+	node.Status.Conditions = []v1.NodeCondition{{Type: "KernelDeadlock", Status: v1.ConditionFalse}}
+	nodeEndpoint := &endpoint.Endpoint{RecordType: "A", DNSName: longNodeName, Targets: endpoint.Targets{"1.1.1.1"}}
+
+	for _, tc := range []struct {
+		title    string
+		nodes    []*v1.Node
+		opts     []NodeSourceOption
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title:    "system info record",
+			nodes:    []*v1.Node{node},
+			opts:     []NodeSourceOption{NodeWithSystemInfo("sysinfo-")},
+			expected: []*endpoint.Endpoint{nodeEndpoint},
+		},
+		{
+			title:    "condition record",
+			nodes:    []*v1.Node{node},
+			opts:     []NodeSourceOption{NodeWithConditionRecord("KernelDeadlock", "condition-")},
+			expected: []*endpoint.Endpoint{nodeEndpoint},
+		},
+		{
+			title:    "placeholder record",
+			opts:     []NodeSourceOption{NodeWithPlaceholder(strings.Repeat("p", 64) + ".example.org")},
+			expected: []*endpoint.Endpoint{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestNodeSource(t, "", tc.nodes, tc.opts...)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// longNodeName is a node name just within the DNS label length limit, so that prefixed
+// record names derived from it exceed the limit.
+var longNodeName = strings.Repeat("n", 60)
+
+// newTestNode creates a node with the given annotations, labels and external IP addresses.
+func newTestNode(name string, annotations, labels map[string]string, externalIPs ...string) *v1.Node {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+	for _, ip := range externalIPs {
+		node.Status.Addresses = append(node.Status.Addresses, v1.NodeAddress{Type: v1.NodeExternalIP, Address: ip})
+	}
+	return node
+}
+
+// newTestNodeSource creates a node source backed by a fake client holding the given nodes.
+func newTestNodeSource(t *testing.T, fqdnTemplate string, nodes []*v1.Node, opts ...NodeSourceOption) Source {
+	t.Helper()
+
+	kubernetes := fake.NewSimpleClientset()
+	for _, node := range nodes {
+		_, err := kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	client, err := NewNodeSource(context.TODO(), kubernetes, "", fqdnTemplate, opts...)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestReverseName(t *testing.T) {
+	for _, tc := range []struct {
+		address     string
+		expected    string
+		expectError bool
+	}{
+		{"192.0.2.10", "10.2.0.192.in-addr.arpa", false},
+		{"2001:db8::567:89ab", "b.a.9.8.7.6.5.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa", false},
+		{"node.example.org", "", true},
+	} {
+		t.Run(tc.address, func(t *testing.T) {
+			name, err := reverseName(tc.address)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, name)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// nodeWebhookRequest is the body posted to the node webhook for each node.
+type nodeWebhookRequest struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// nodeWebhookResponse is the body expected from the node webhook.
+type nodeWebhookResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+// nodeWebhookDecision is a cached decision of the node webhook.
+type nodeWebhookDecision struct {
+	allowed bool
+	expires time.Time
+}
+
+// nodeWebhook asks an external service whether nodes may be published and caches its decisions.
+type nodeWebhook struct {
+	url      string
+	client   *http.Client
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	cache map[string]nodeWebhookDecision
+	mutex sync.Mutex
+}
+
+func newNodeWebhook(url string, timeout, cacheTTL time.Duration) *nodeWebhook {
+	return &nodeWebhook{
+		url:      url,
+		client:   &http.Client{Timeout: timeout},
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    map[string]nodeWebhookDecision{},
+	}
+}
+
+// allowed returns whether the node may be published. Decisions are cached per node name. If the
+// webhook fails, the last known decision is used, or the node is denied if there is none.
+// The lock is not held while the webhook is called, so that a slow node doesn't block others.
+func (wh *nodeWebhook) allowed(ctx context.Context, node *v1.Node) bool {
+	wh.mutex.Lock()
+	cached, ok := wh.cache[node.Name]
+	wh.mutex.Unlock()
+	if ok && wh.now().Before(cached.expires) {
+		return cached.allowed
+	}
+
+	allowed, err := wh.review(ctx, node)
+	if err != nil {
+		log.Warnf("Failed to check node %s with webhook, using last known decision %t: %v", node.Name, cached.allowed, err)
+		return cached.allowed
+	}
+
+	wh.mutex.Lock()
+	defer wh.mutex.Unlock()
+
+	wh.cache[node.Name] = nodeWebhookDecision{allowed: allowed, expires: wh.now().Add(wh.cacheTTL)}
+	return allowed
+}
+
+// prune removes the cached decisions of nodes other than the given ones, e.g. deleted nodes.
+func (wh *nodeWebhook) prune(nodes []*v1.Node) {
+	names := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		names[node.Name] = true
+	}
+
+	wh.mutex.Lock()
+	defer wh.mutex.Unlock()
+
+	for name := range wh.cache {
+		if !names[name] {
+			delete(wh.cache, name)
+		}
+	}
+}
+
+// review posts the node to the webhook and returns its decision.
+func (wh *nodeWebhook) review(ctx context.Context, node *v1.Node) (bool, error) {
+	body, err := json.Marshal(nodeWebhookRequest{
+		Name:        node.Name,
+		Labels:      node.Labels,
+		Annotations: node.Annotations,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var review nodeWebhookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&review); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return review.Allowed, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strconv"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// NodeCountLabelKey is the name of the label holding the number of nodes contributing targets to an endpoint.
+const NodeCountLabelKey = "node-count"
+
+// nodeCountSource is a Source that labels the A records of its wrapped source
+// with the number of nodes contributing targets.
+type nodeCountSource struct {
+	source Source
+}
+
+// NewNodeCountSource creates a new nodeCountSource wrapping the provided Source.
+func NewNodeCountSource(source Source) Source {
+	return &nodeCountSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of them with
+// A records labeled by their number of distinct targets, each node contributing one target.
+func (ms *nodeCountSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType == endpoint.RecordTypeA {
+			ep = ep.DeepCopy()
+			if ep.Labels == nil {
+				ep.Labels = endpoint.NewLabels()
+			}
+			distinct := map[string]bool{}
+			for _, t := range ep.Targets {
+				distinct[t] = true
+			}
+			ep.Labels[NodeCountLabelKey] = strconv.Itoa(len(distinct))
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *nodeCountSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
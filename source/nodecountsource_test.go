@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that nodeCountSource is a Source
+var _ Source = &nodeCountSource{}
+
+func TestNodeCountSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"single node record is labeled with one",
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{NodeCountLabelKey: "1"}},
+			},
+		},
+		{
+			"aggregate record is labeled with its target count",
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2", "3.3.3.3"}, Labels: endpoint.Labels{"foo": "bar"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2", "3.3.3.3"}, Labels: endpoint.Labels{"foo": "bar", NodeCountLabelKey: "3"}},
+			},
+		},
+		{
+			"duplicate targets are counted once",
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.1"}, Labels: endpoint.Labels{NodeCountLabelKey: "1"}},
+			},
+		},
+		{
+			"non A records are not labeled",
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewNodeCountSource(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.NotContains(t, tc.endpoints[0].Labels, NodeCountLabelKey, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
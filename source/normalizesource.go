@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// normalizeSource is a Source that normalizes the DNS names of the endpoints of its wrapped source.
+type normalizeSource struct {
+	source Source
+}
+
+// NewNormalizeSource creates a new normalizeSource wrapping the provided Source.
+func NewNormalizeSource(source Source) Source {
+	return &normalizeSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of all of them with
+// their DNS name lowercased, consecutive dots collapsed and the trailing dot removed.
+// Targets are left alone.
+func (ms *normalizeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		ep = ep.DeepCopy()
+		ep.DNSName = normalizeDNSName(ep.DNSName)
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// normalizeDNSName returns the lowercase DNS name without empty labels and trailing dot.
+func normalizeDNSName(dnsName string) string {
+	dnsName = strings.ToLower(dnsName)
+	for strings.Contains(dnsName, "..") {
+		dnsName = strings.ReplaceAll(dnsName, "..", ".")
+	}
+	return strings.TrimSuffix(dnsName, ".")
+}
+
+func (ms *normalizeSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
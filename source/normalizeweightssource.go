@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// weightProviderSpecificKey is the provider specific property holding the weight of a record.
+const weightProviderSpecificKey = "aws/weight"
+
+// normalizeWeightsSource is a Source that scales the weights of the weighted records of its
+// wrapped source so that they sum up to a fixed total.
+type normalizeWeightsSource struct {
+	source Source
+	total  int
+}
+
+// NewNormalizeWeightsSource creates a new normalizeWeightsSource wrapping the provided Source.
+// The total must be positive.
+func NewNormalizeWeightsSource(source Source, total int) (Source, error) {
+	if total <= 0 {
+		return nil, fmt.Errorf("invalid weight total %d, must be positive", total)
+	}
+	return &normalizeWeightsSource{source: source, total: total}, nil
+}
+
+// Endpoints collects endpoints from its wrapped source and scales the weights within each group
+// of records sharing DNS name and record type but differing in their set identifier.
+// Rounding remainders go to the members with the largest fractional parts, so that the weights
+// always sum up to the total. Groups with a zero or invalid weight sum are left untouched.
+func (ms *normalizeWeightsSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[endpointKey][]int{}
+	for i, ep := range endpoints {
+		if ep.SetIdentifier == "" {
+			continue
+		}
+		if _, ok := ep.GetProviderSpecificProperty(weightProviderSpecificKey); !ok {
+			continue
+		}
+		key := endpointKey{dnsName: ep.DNSName, recordType: ep.RecordType}
+		groups[key] = append(groups[key], i)
+	}
+
+	result := make([]*endpoint.Endpoint, len(endpoints))
+	copy(result, endpoints)
+	for key, members := range groups {
+		weights, err := ms.normalize(endpoints, members)
+		if err != nil {
+			log.Warnf("Not normalizing weights of %s %s records: %v", key.dnsName, key.recordType, err)
+			continue
+		}
+		for i, member := range members {
+			result[member] = withWeight(endpoints[member], weights[i])
+		}
+	}
+
+	return result, nil
+}
+
+// normalize returns the scaled weights of the given group members.
+// Weights come from user input and may be arbitrarily large, so they are scaled with
+// arbitrary precision to avoid overflows.
+func (ms *normalizeWeightsSource) normalize(endpoints []*endpoint.Endpoint, members []int) ([]int64, error) {
+	weights := make([]*big.Int, len(members))
+	sum := new(big.Int)
+	for i, member := range members {
+		prop, _ := endpoints[member].GetProviderSpecificProperty(weightProviderSpecificKey)
+		weight, err := strconv.ParseInt(prop.Value, 10, 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q of set identifier %s", prop.Value, endpoints[member].SetIdentifier)
+		}
+		weights[i] = big.NewInt(weight)
+		sum.Add(sum, weights[i])
+	}
+	if sum.Sign() == 0 {
+		return nil, fmt.Errorf("weights sum up to zero")
+	}
+
+	total := big.NewInt(int64(ms.total))
+	scaled := make([]int64, len(weights))
+	remainders := make([]*big.Int, len(weights))
+	assigned := int64(0)
+	for i, weight := range weights {
+		quotient, remainder := new(big.Int).QuoRem(new(big.Int).Mul(weight, total), sum, new(big.Int))
+		scaled[i] = quotient.Int64()
+		remainders[i] = remainder
+		assigned += scaled[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]].Cmp(remainders[order[j]]) > 0
+	})
+	for i := int64(0); i < int64(ms.total)-assigned; i++ {
+		scaled[order[i]]++
+	}
+
+	return scaled, nil
+}
+
+// withWeight returns a copy of the endpoint with its weight replaced.
+func withWeight(ep *endpoint.Endpoint, weight int64) *endpoint.Endpoint {
+	ep = ep.DeepCopy()
+	for i := range ep.ProviderSpecific {
+		if ep.ProviderSpecific[i].Name == weightProviderSpecificKey {
+			ep.ProviderSpecific[i].Value = strconv.FormatInt(weight, 10)
+		}
+	}
+	return ep
+}
+
+func (ms *normalizeWeightsSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that normalizeWeightsSource is a Source
+var _ Source = &normalizeWeightsSource{}
+
+func newWeightedEndpoint(dnsName, setIdentifier, target, weight string) *endpoint.Endpoint {
+	return endpoint.NewEndpoint(dnsName, endpoint.RecordTypeA, target).
+		WithSetIdentifier(setIdentifier).
+		WithProviderSpecific(weightProviderSpecificKey, weight)
+}
+
+func TestNormalizeWeightsSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		total     int
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"weights are scaled to the total",
+			100,
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "1"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "3"),
+			},
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "25"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "75"),
+			},
+		},
+		{
+			"rounding remainders go to the largest fractions",
+			100,
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "1"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "1"),
+				newWeightedEndpoint("foo.example.org", "c", "3.3.3.3", "1"),
+			},
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "34"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "33"),
+				newWeightedEndpoint("foo.example.org", "c", "3.3.3.3", "33"),
+			},
+		},
+		{
+			"groups are normalized independently",
+			10,
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "20"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "80"),
+				newWeightedEndpoint("bar.example.org", "a", "3.3.3.3", "7"),
+			},
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "2"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "8"),
+				newWeightedEndpoint("bar.example.org", "a", "3.3.3.3", "10"),
+			},
+		},
+		{
+			"huge weights do not overflow",
+			100,
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "9223372036854775807"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "9223372036854775807"),
+				newWeightedEndpoint("foo.example.org", "c", "3.3.3.3", "1"),
+			},
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "50"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "50"),
+				newWeightedEndpoint("foo.example.org", "c", "3.3.3.3", "0"),
+			},
+		},
+		{
+			"groups with zero weights are left untouched",
+			100,
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "0"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "0"),
+			},
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "0"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "0"),
+			},
+		},
+		{
+			"groups with invalid weights are left untouched",
+			100,
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "1"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "heavy"),
+			},
+			[]*endpoint.Endpoint{
+				newWeightedEndpoint("foo.example.org", "a", "1.1.1.1", "1"),
+				newWeightedEndpoint("foo.example.org", "b", "2.2.2.2", "heavy"),
+			},
+		},
+		{
+			"unweighted records are passed through",
+			100,
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+				endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "2.2.2.2").WithSetIdentifier("a"),
+			},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+				endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "2.2.2.2").WithSetIdentifier("a"),
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].DeepCopy()
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source, err := NewNormalizeWeightsSource(mockSource, tc.total)
+			require.NoError(t, err)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0], "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNormalizeWeightsSourceInvalidTotal(t *testing.T) {
+	for _, total := range []int{0, -100} {
+		_, err := NewNormalizeWeightsSource(new(testutils.MockSource), total)
+		require.Error(t, err, "total %d must be rejected", total)
+	}
+}
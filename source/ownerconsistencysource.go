@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// ownerConsistencySource is a Source that makes the TXT and address endpoints of each DNS name
+// of its wrapped source carry the same owner label, keeping the registry consistent.
+type ownerConsistencySource struct {
+	source Source
+}
+
+// NewOwnerConsistencySource creates a new ownerConsistencySource wrapping the provided Source.
+func NewOwnerConsistencySource(source Source) Source {
+	return &ownerConsistencySource{source: source}
+}
+
+// isOwnedRecordType returns true for the record types whose owner labels are reconciled.
+func isOwnedRecordType(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeTXT, endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME:
+		return true
+	}
+	return false
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them with the owner label of
+// the TXT, A, AAAA and CNAME endpoints of each DNS name reconciled. The owner of the TXT endpoint
+// wins, otherwise the owner of the first endpoint defining one is used. Endpoints with a missing
+// or different owner are copied and updated, all other endpoints are passed through untouched.
+func (ms *ownerConsistencySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := map[string]string{}
+	for _, ep := range endpoints {
+		owner := ep.Labels[endpoint.OwnerLabelKey]
+		if owner == "" || !isOwnedRecordType(ep.RecordType) {
+			continue
+		}
+		if _, ok := owners[ep.DNSName]; !ok || ep.RecordType == endpoint.RecordTypeTXT {
+			owners[ep.DNSName] = owner
+		}
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		owner, ok := owners[ep.DNSName]
+		if ok && isOwnedRecordType(ep.RecordType) && ep.Labels[endpoint.OwnerLabelKey] != owner {
+			if current := ep.Labels[endpoint.OwnerLabelKey]; current != "" {
+				log.Warnf("Replacing owner %q of endpoint %s with owner %q of its name", current, ep, owner)
+			}
+			ep = ep.DeepCopy()
+			if ep.Labels == nil {
+				ep.Labels = endpoint.NewLabels()
+			}
+			ep.Labels[endpoint.OwnerLabelKey] = owner
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *ownerConsistencySource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that ownerConsistencySource is a Source
+var _ Source = &ownerConsistencySource{}
+
+func TestOwnerConsistencySource(t *testing.T) {
+	owned := func(owner string) endpoint.Labels {
+		return endpoint.Labels{endpoint.OwnerLabelKey: owner}
+	}
+
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"owner of the TXT endpoint is copied to address endpoints",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"owner"}, Labels: owned("cluster-a")},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, Labels: endpoint.Labels{}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: owned("cluster-a")},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"owner"}, Labels: owned("cluster-a")},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, Labels: owned("cluster-a")},
+			},
+		},
+		{
+			"owner of the address endpoint is copied to the TXT endpoint",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: owned("cluster-a")},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"owner"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: owned("cluster-a")},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"owner"}, Labels: owned("cluster-a")},
+			},
+		},
+		{
+			"mismatched owners are reconciled to the owner of the TXT endpoint",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.OwnerLabelKey:    "cluster-b",
+					endpoint.ResourceLabelKey: "node/node1",
+				}},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"owner"}, Labels: owned("cluster-a")},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.OwnerLabelKey:    "cluster-a",
+					endpoint.ResourceLabelKey: "node/node1",
+				}},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"owner"}, Labels: owned("cluster-a")},
+			},
+		},
+		{
+			"other names and record types are left alone",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"owner"}, Labels: owned("cluster-a")},
+				{DNSName: "foo.example.org", RecordType: "SRV", Targets: endpoint.Targets{"0 50 80 foo.example.org"}, Labels: owned("cluster-b")},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}, Labels: owned("cluster-b")},
+				{DNSName: "baz.example.org", RecordType: "A", Targets: endpoint.Targets{"9.9.9.9"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"owner"}, Labels: owned("cluster-a")},
+				{DNSName: "foo.example.org", RecordType: "SRV", Targets: endpoint.Targets{"0 50 80 foo.example.org"}, Labels: owned("cluster-b")},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}, Labels: owned("cluster-b")},
+				{DNSName: "baz.example.org", RecordType: "A", Targets: endpoint.Targets{"9.9.9.9"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].Labels[endpoint.OwnerLabelKey]
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewOwnerConsistencySource(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].Labels[endpoint.OwnerLabelKey], "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
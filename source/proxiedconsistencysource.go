@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// proxiedConsistencySource is a Source that makes the A and AAAA records of a name of its
+// wrapped source agree on whether they are proxied by Cloudflare.
+type proxiedConsistencySource struct {
+	source Source
+}
+
+// NewProxiedConsistencySource creates a new proxiedConsistencySource wrapping the provided Source.
+func NewProxiedConsistencySource(source Source) Source {
+	return &proxiedConsistencySource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and, for names with both A and AAAA
+// records, copies the Cloudflare proxied property from the record setting it to the other.
+// If both set it differently, the value of the A record wins and a warning is logged.
+// Records with different set identifiers are reconciled separately.
+func (ms *proxiedConsistencySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	families := map[dualStackKey]map[string]bool{}
+	proxied := map[dualStackKey]string{}
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA {
+			continue
+		}
+		key := dualStackKey{dnsName: ep.DNSName, setIdentifier: ep.SetIdentifier}
+		if families[key] == nil {
+			families[key] = map[string]bool{}
+		}
+		families[key][ep.RecordType] = true
+
+		prop, ok := ep.GetProviderSpecificProperty(CloudflareProxiedKey)
+		if !ok {
+			continue
+		}
+		if _, known := proxied[key]; !known || ep.RecordType == endpoint.RecordTypeA {
+			proxied[key] = prop.Value
+		}
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		key := dualStackKey{dnsName: ep.DNSName, setIdentifier: ep.SetIdentifier}
+		value, ok := proxied[key]
+		if !ok || len(families[key]) < 2 {
+			result = append(result, ep)
+			continue
+		}
+		result = append(result, withProxied(ep, value))
+	}
+
+	return result, nil
+}
+
+// withProxied returns the endpoint with the given proxied property, copying it if needed.
+func withProxied(ep *endpoint.Endpoint, value string) *endpoint.Endpoint {
+	prop, ok := ep.GetProviderSpecificProperty(CloudflareProxiedKey)
+	if ok && prop.Value == value {
+		return ep
+	}
+
+	ep = ep.DeepCopy()
+	if !ok {
+		return ep.WithProviderSpecific(CloudflareProxiedKey, value)
+	}
+
+	log.Warnf("Overriding proxied property %s of %s with %s of its A record", prop.Value, ep, value)
+	for i := range ep.ProviderSpecific {
+		if ep.ProviderSpecific[i].Name == CloudflareProxiedKey {
+			ep.ProviderSpecific[i].Value = value
+		}
+	}
+	return ep
+}
+
+func (ms *proxiedConsistencySource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
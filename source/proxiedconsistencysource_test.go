@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that proxiedConsistencySource is a Source
+var _ Source = &proxiedConsistencySource{}
+
+func TestProxiedConsistencySource(t *testing.T) {
+	proxied := func(value string) endpoint.ProviderSpecific {
+		return endpoint.ProviderSpecific{{Name: CloudflareProxiedKey, Value: value}}
+	}
+
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"proxied property of the A record is copied to the AAAA record",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: proxied("true")},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, ProviderSpecific: proxied("true")},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: proxied("true")},
+			},
+		},
+		{
+			"proxied property of the AAAA record is copied to the A record",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/weight", Value: "10"}}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, ProviderSpecific: proxied("false")},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/weight", Value: "10"}, {Name: CloudflareProxiedKey, Value: "false"}}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, ProviderSpecific: proxied("false")},
+			},
+		},
+		{
+			"conflicting proxied properties are reconciled to the A record",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, ProviderSpecific: proxied("true")},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: proxied("false")},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, ProviderSpecific: proxied("false")},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: proxied("false")},
+			},
+		},
+		{
+			"single stack names and other records are left alone",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: proxied("true")},
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"bar.example.org"}},
+				{DNSName: "bar.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"2.2.2.2"}, ProviderSpecific: proxied("true")},
+				{DNSName: "bar.example.org", RecordType: "AAAA", SetIdentifier: "b", Targets: endpoint.Targets{"2001:db8::2"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}, ProviderSpecific: proxied("true")},
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"bar.example.org"}},
+				{DNSName: "bar.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"2.2.2.2"}, ProviderSpecific: proxied("true")},
+				{DNSName: "bar.example.org", RecordType: "AAAA", SetIdentifier: "b", Targets: endpoint.Targets{"2001:db8::2"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].DeepCopy()
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewProxiedConsistencySource(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0], "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// annotationRecord describes a record type whose data can be defined by an annotation.
+type annotationRecord struct {
+	annotationKey string
+	recordType    string
+	parse         func(string) (string, error)
+}
+
+// annotationRecords lists the record types that can be attached to a hostname via annotations.
+var annotationRecords = []annotationRecord{
+	{certAnnotationKey, endpoint.RecordTypeCERT, parseCertData},
+	{dsAnnotationKey, endpoint.RecordTypeDS, parseDSData},
+	{openPGPKeyAnnotationKey, endpoint.RecordTypeOPENPGPKEY, parseOpenPGPKeyData},
+	{aplAnnotationKey, endpoint.RecordTypeAPL, parseAPLData},
+	{hinfoAnnotationKey, endpoint.RecordTypeHINFO, parseHINFOData},
+}
+
+// getRecordDataFromAnnotations gets the record data of the given record type from the
+// optional annotation with the given key. Multiple records are separated by commas,
+// each of them is validated by parse and returned in its normalized presentation format.
+// Returns nil if the annotation is not present.
+func getRecordDataFromAnnotations(annotations map[string]string, key, recordType string, parse func(string) (string, error)) (endpoint.Targets, error) {
+	annotation, exists := annotations[key]
+	if !exists {
+		return nil, nil
+	}
+
+	var targets endpoint.Targets
+	for _, value := range splitRecordData(annotation) {
+		data, err := parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s record data %q: %w", recordType, strings.TrimSpace(value), err)
+		}
+		targets = append(targets, data)
+	}
+	return targets, nil
+}
+
+// splitRecordData splits the comma separated records of an annotation, keeping the commas
+// within double quoted values like the HINFO cpu "Intel, x86".
+func splitRecordData(annotation string) []string {
+	var values []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(annotation); i++ {
+		switch annotation[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				values = append(values, annotation[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(values, annotation[start:])
+}
+
+// annotationRecordEndpoints returns the endpoints of the record types in annotationRecords
+// defined by the annotations for the given hostname. Invalid record data is logged and skipped.
+func annotationRecordEndpoints(annotations map[string]string, hostname string, ttl endpoint.TTL) []*endpoint.Endpoint {
+	var endpoints []*endpoint.Endpoint
+	for _, record := range annotationRecords {
+		data, err := getRecordDataFromAnnotations(annotations, record.annotationKey, record.recordType, record.parse)
+		if err != nil {
+			log.Warnf("Skipping %s record for %s: %v", record.recordType, hostname, err)
+			continue
+		}
+		if len(data) > 0 {
+			endpoints = append(endpoints, &endpoint.Endpoint{
+				DNSName:    strings.TrimSuffix(hostname, "."),
+				RecordType: record.recordType,
+				RecordTTL:  ttl,
+				Targets:    data,
+				Labels:     endpoint.NewLabels(),
+			})
+		}
+	}
+	return endpoints
+}
+
+// parseCertData parses CERT record data in the form "<type> <keytag> <algorithm> <certificate>"
+// as defined in RFC 4398. Type and algorithm accept either mnemonics or numeric values,
+// the certificate must be base64 encoded and may be split by whitespace.
+func parseCertData(value string) (string, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 4 {
+		return "", fmt.Errorf("expected type, key tag, algorithm and certificate")
+	}
+
+	certType := strings.ToUpper(fields[0])
+	if _, ok := dns.StringToCertType[certType]; !ok {
+		if _, err := strconv.ParseUint(certType, 10, 16); err != nil {
+			return "", fmt.Errorf("unknown certificate type %q", fields[0])
+		}
+	}
+
+	if _, err := strconv.ParseUint(fields[1], 10, 16); err != nil {
+		return "", fmt.Errorf("key tag %q must be between 0 and 65535", fields[1])
+	}
+
+	algorithm, err := parseAlgorithm(fields[2])
+	if err != nil {
+		return "", err
+	}
+
+	certificate := strings.Join(fields[3:], "")
+	if _, err := base64.StdEncoding.DecodeString(certificate); err != nil {
+		return "", fmt.Errorf("certificate is not valid base64: %w", err)
+	}
+
+	return strings.Join([]string{certType, fields[1], algorithm, certificate}, " "), nil
+}
+
+// parseAlgorithm validates a DNSSEC algorithm given either as a mnemonic or as a number
+// between 0 and 255.
+func parseAlgorithm(value string) (string, error) {
+	algorithm := strings.ToUpper(value)
+	if _, ok := dns.StringToAlgorithm[algorithm]; ok {
+		return algorithm, nil
+	}
+	if _, err := strconv.ParseUint(algorithm, 10, 8); err != nil {
+		return "", fmt.Errorf("unknown algorithm %q", value)
+	}
+	return algorithm, nil
+}
+
+// dsDigestLengths maps the well-known DS digest types to the length of their digest in bytes.
+var dsDigestLengths = map[uint64]int{
+	1: 20, // SHA-1
+	2: 32, // SHA-256
+	4: 48, // SHA-384
+}
+
+// parseDSData parses DS record data in the form "<keytag> <algorithm> <digesttype> <digest>"
+// as defined in RFC 4034. The digest must be hex encoded and may be split by whitespace,
+// its length is checked for the well-known digest types.
+func parseDSData(value string) (string, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 4 {
+		return "", fmt.Errorf("expected key tag, algorithm, digest type and digest")
+	}
+
+	if _, err := strconv.ParseUint(fields[0], 10, 16); err != nil {
+		return "", fmt.Errorf("key tag %q must be between 0 and 65535", fields[0])
+	}
+
+	algorithm, err := parseAlgorithm(fields[1])
+	if err != nil {
+		return "", err
+	}
+
+	digestType, err := strconv.ParseUint(fields[2], 10, 8)
+	if err != nil {
+		return "", fmt.Errorf("digest type %q must be between 0 and 255", fields[2])
+	}
+
+	digest := strings.ToUpper(strings.Join(fields[3:], ""))
+	decoded, err := hex.DecodeString(digest)
+	if err != nil {
+		return "", fmt.Errorf("digest is not valid hex: %w", err)
+	}
+	if length, ok := dsDigestLengths[digestType]; ok && len(decoded) != length {
+		return "", fmt.Errorf("digest of type %d must be %d bytes long, got %d", digestType, length, len(decoded))
+	}
+
+	return strings.Join([]string{fields[0], algorithm, fields[2], digest}, " "), nil
+}
+
+// parseOpenPGPKeyData parses OPENPGPKEY record data as defined in RFC 7929. The public key
+// must be base64 encoded and may be split by whitespace.
+func parseOpenPGPKeyData(value string) (string, error) {
+	key := strings.Join(strings.Fields(value), "")
+	if key == "" {
+		return "", fmt.Errorf("expected public key")
+	}
+	if _, err := base64.StdEncoding.DecodeString(key); err != nil {
+		return "", fmt.Errorf("public key is not valid base64: %w", err)
+	}
+	return key, nil
+}
+
+// aplFamilies maps the address families supported in APL records to their IANA numbers.
+var aplFamilies = map[string]bool{
+	"1": true, // IPv4
+	"2": true, // IPv6
+}
+
+// parseAPLData parses APL record data as defined in RFC 3123, a whitespace separated list of
+// address prefixes in the form "[!]<family>:<address>/<prefix>". The family may be omitted and
+// is then derived from the address. Prefixes are returned with their family.
+func parseAPLData(value string) (string, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("expected address prefixes")
+	}
+
+	items := make([]string, 0, len(fields))
+	for _, field := range fields {
+		negation := ""
+		if strings.HasPrefix(field, "!") {
+			negation = "!"
+			field = field[1:]
+		}
+
+		family := ""
+		if i := strings.Index(field, ":"); i >= 0 && aplFamilies[field[:i]] {
+			family, field = field[:i], field[i+1:]
+		}
+
+		prefix, err := netip.ParsePrefix(field)
+		if err != nil {
+			return "", fmt.Errorf("invalid address prefix %q: %w", field, err)
+		}
+		addrFamily := "1"
+		if !prefix.Addr().Is4() {
+			addrFamily = "2"
+		}
+		if family != "" && family != addrFamily {
+			return "", fmt.Errorf("address prefix %s doesn't belong to address family %s", field, family)
+		}
+
+		items = append(items, negation+addrFamily+":"+prefix.String())
+	}
+	return strings.Join(items, " "), nil
+}
+
+// parseHINFOData parses HINFO record data in the form "<cpu> <os>" as defined in RFC 1035.
+// Values containing whitespace must be enclosed in double quotes. Both values are returned
+// quoted and must not be longer than 255 characters.
+func parseHINFOData(value string) (string, error) {
+	fields, err := splitCharacterStrings(value)
+	if err != nil {
+		return "", err
+	}
+	if len(fields) != 2 {
+		return "", fmt.Errorf("expected cpu and os, got %d values", len(fields))
+	}
+
+	for _, field := range fields {
+		if field == "" {
+			return "", fmt.Errorf("cpu and os must not be empty")
+		}
+		if len(field) > 255 {
+			return "", fmt.Errorf("value %q is longer than 255 characters", field)
+		}
+	}
+	return quoteCharacterString(fields[0]) + " " + quoteCharacterString(fields[1]), nil
+}
+
+// quoteCharacterString returns the value as quoted character-string in the presentation
+// format of RFC 1035: quotes and backslashes are escaped with a backslash, and bytes outside
+// of printable ASCII as \DDD with the decimal value of the byte.
+func quoteCharacterString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// splitCharacterStrings splits whitespace separated values, keeping the whitespace within
+// double quoted values.
+func splitCharacterStrings(value string) ([]string, error) {
+	var fields []string
+	value = strings.TrimSpace(value)
+	for value != "" {
+		if value[0] != '"' {
+			end := strings.IndexAny(value, " \t")
+			if end < 0 {
+				end = len(value)
+			}
+			field := value[:end]
+			if strings.Contains(field, "\"") {
+				return nil, fmt.Errorf("unexpected quote in %q", field)
+			}
+			fields = append(fields, field)
+			value = strings.TrimSpace(value[end:])
+			continue
+		}
+
+		end := strings.Index(value[1:], "\"")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated quote in %q", value)
+		}
+		fields = append(fields, value[1:end+1])
+		value = value[end+2:]
+		if value != "" && value[0] != ' ' && value[0] != '\t' {
+			return nil, fmt.Errorf("missing whitespace after quoted value in %q", value)
+		}
+		value = strings.TrimSpace(value)
+	}
+	return fields, nil
+}
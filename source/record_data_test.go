@@ -0,0 +1,379 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestGetCertDataFromAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		annotations map[string]string
+		expected    endpoint.Targets
+		expectError bool
+	}{
+		{
+			title:       "annotation not present",
+			annotations: map[string]string{"foo": "bar"},
+		},
+		{
+			title:       "mnemonic type and algorithm",
+			annotations: map[string]string{certAnnotationKey: "pkix 12345 rsasha256 MIIBIjANBgkqhkiG"},
+			expected:    endpoint.Targets{"PKIX 12345 RSASHA256 MIIBIjANBgkqhkiG"},
+		},
+		{
+			title:       "numeric type and algorithm",
+			annotations: map[string]string{certAnnotationKey: "1 0 8 MIIBIjANBgkqhkiG"},
+			expected:    endpoint.Targets{"1 0 8 MIIBIjANBgkqhkiG"},
+		},
+		{
+			title:       "certificate split by whitespace",
+			annotations: map[string]string{certAnnotationKey: "PGP 1 RSASHA1 MIIBIjAN BgkqhkiG"},
+			expected:    endpoint.Targets{"PGP 1 RSASHA1 MIIBIjANBgkqhkiG"},
+		},
+		{
+			title:       "multiple records",
+			annotations: map[string]string{certAnnotationKey: "PKIX 1 8 MIIBIjANBgkqhkiG, PGP 2 8 MIIBIjANBgkqhkiG"},
+			expected:    endpoint.Targets{"PKIX 1 8 MIIBIjANBgkqhkiG", "PGP 2 8 MIIBIjANBgkqhkiG"},
+		},
+		{
+			title:       "missing certificate",
+			annotations: map[string]string{certAnnotationKey: "PKIX 1 8"},
+			expectError: true,
+		},
+		{
+			title:       "unknown type",
+			annotations: map[string]string{certAnnotationKey: "FOO 1 8 MIIBIjANBgkqhkiG"},
+			expectError: true,
+		},
+		{
+			title:       "key tag out of range",
+			annotations: map[string]string{certAnnotationKey: "PKIX 65536 8 MIIBIjANBgkqhkiG"},
+			expectError: true,
+		},
+		{
+			title:       "unknown algorithm",
+			annotations: map[string]string{certAnnotationKey: "PKIX 1 FOO MIIBIjANBgkqhkiG"},
+			expectError: true,
+		},
+		{
+			title:       "algorithm out of range",
+			annotations: map[string]string{certAnnotationKey: "PKIX 1 256 MIIBIjANBgkqhkiG"},
+			expectError: true,
+		},
+		{
+			title:       "certificate is not base64",
+			annotations: map[string]string{certAnnotationKey: "PKIX 1 8 !!!"},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			targets, err := getRecordDataFromAnnotations(tc.annotations, certAnnotationKey, endpoint.RecordTypeCERT, parseCertData)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, targets)
+		})
+	}
+}
+
+func TestGetDSDataFromAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		annotations map[string]string
+		expected    endpoint.Targets
+		expectError bool
+	}{
+		{
+			title:       "annotation not present",
+			annotations: map[string]string{"foo": "bar"},
+		},
+		{
+			title:       "SHA-1 digest",
+			annotations: map[string]string{dsAnnotationKey: "60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118"},
+			expected:    endpoint.Targets{"60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118"},
+		},
+		{
+			title:       "SHA-256 digest split by whitespace in lower case",
+			annotations: map[string]string{dsAnnotationKey: "2371 ecdsap256sha256 2 1f987cc6583e92df0890718c42 59532309e3bda6cda9d1a0c5ec6d9c2d5b6c00"},
+			expected:    endpoint.Targets{"2371 ECDSAP256SHA256 2 1F987CC6583E92DF0890718C4259532309E3BDA6CDA9D1A0C5EC6D9C2D5B6C00"},
+		},
+		{
+			title:       "unknown digest type is not length checked",
+			annotations: map[string]string{dsAnnotationKey: "1 8 200 ABCD"},
+			expected:    endpoint.Targets{"1 8 200 ABCD"},
+		},
+		{
+			title:       "missing digest",
+			annotations: map[string]string{dsAnnotationKey: "60485 5 1"},
+			expectError: true,
+		},
+		{
+			title:       "key tag out of range",
+			annotations: map[string]string{dsAnnotationKey: "65536 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118"},
+			expectError: true,
+		},
+		{
+			title:       "unknown algorithm",
+			annotations: map[string]string{dsAnnotationKey: "60485 FOO 1 2BB183AF5F22588179A53B0A98631FAD1A292118"},
+			expectError: true,
+		},
+		{
+			title:       "digest type out of range",
+			annotations: map[string]string{dsAnnotationKey: "60485 5 256 2BB183AF5F22588179A53B0A98631FAD1A292118"},
+			expectError: true,
+		},
+		{
+			title:       "digest is not hex",
+			annotations: map[string]string{dsAnnotationKey: "60485 5 1 XYZ183AF5F22588179A53B0A98631FAD1A292118"},
+			expectError: true,
+		},
+		{
+			title:       "digest length does not match digest type",
+			annotations: map[string]string{dsAnnotationKey: "60485 5 2 2BB183AF5F22588179A53B0A98631FAD1A292118"},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			targets, err := getRecordDataFromAnnotations(tc.annotations, dsAnnotationKey, endpoint.RecordTypeDS, parseDSData)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, targets)
+		})
+	}
+}
+
+func TestGetOpenPGPKeyDataFromAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		annotations map[string]string
+		expected    endpoint.Targets
+		expectError bool
+	}{
+		{
+			title:       "annotation not present",
+			annotations: map[string]string{"foo": "bar"},
+		},
+		{
+			title:       "single key",
+			annotations: map[string]string{openPGPKeyAnnotationKey: "mJmam5ydnp+goaKjpKWmp6ipqqusra6vsLGys7S1tre4ubq7vL2+v8DBwsPExcbHyMnKy8w="},
+			expected:    endpoint.Targets{"mJmam5ydnp+goaKjpKWmp6ipqqusra6vsLGys7S1tre4ubq7vL2+v8DBwsPExcbHyMnKy8w="},
+		},
+		{
+			title:       "key split by whitespace",
+			annotations: map[string]string{openPGPKeyAnnotationKey: " mJmam5ydnp+goaKjpKWmp6ipqqus\n\tra6vsLGys7S1tre4ubq7vL2+v8DBwsPExcbHyMnKy8w= "},
+			expected:    endpoint.Targets{"mJmam5ydnp+goaKjpKWmp6ipqqusra6vsLGys7S1tre4ubq7vL2+v8DBwsPExcbHyMnKy8w="},
+		},
+		{
+			title:       "multiple keys",
+			annotations: map[string]string{openPGPKeyAnnotationKey: "AQID,BAUG"},
+			expected:    endpoint.Targets{"AQID", "BAUG"},
+		},
+		{
+			title:       "empty key",
+			annotations: map[string]string{openPGPKeyAnnotationKey: "AQID, "},
+			expectError: true,
+		},
+		{
+			title:       "key is not base64",
+			annotations: map[string]string{openPGPKeyAnnotationKey: "not-base64!"},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			targets, err := getRecordDataFromAnnotations(tc.annotations, openPGPKeyAnnotationKey, endpoint.RecordTypeOPENPGPKEY, parseOpenPGPKeyData)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, targets)
+		})
+	}
+}
+
+func TestGetAPLDataFromAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		annotations map[string]string
+		expected    endpoint.Targets
+		expectError bool
+	}{
+		{
+			title:       "annotation not present",
+			annotations: map[string]string{"foo": "bar"},
+		},
+		{
+			title:       "prefixes with families and negation",
+			annotations: map[string]string{aplAnnotationKey: "1:192.168.32.0/21 !1:192.168.38.0/28"},
+			expected:    endpoint.Targets{"1:192.168.32.0/21 !1:192.168.38.0/28"},
+		},
+		{
+			title:       "families are derived from the addresses",
+			annotations: map[string]string{aplAnnotationKey: "10.0.0.0/8 !2001:db8::/32"},
+			expected:    endpoint.Targets{"1:10.0.0.0/8 !2:2001:db8::/32"},
+		},
+		{
+			title:       "IPv6 prefix with family",
+			annotations: map[string]string{aplAnnotationKey: "2:2001:db8::/32"},
+			expected:    endpoint.Targets{"2:2001:db8::/32"},
+		},
+		{
+			title:       "multiple records",
+			annotations: map[string]string{aplAnnotationKey: "1:10.0.0.0/8, 1:172.16.0.0/12"},
+			expected:    endpoint.Targets{"1:10.0.0.0/8", "1:172.16.0.0/12"},
+		},
+		{
+			title:       "missing prefix length",
+			annotations: map[string]string{aplAnnotationKey: "1:10.0.0.1"},
+			expectError: true,
+		},
+		{
+			title:       "prefix length out of range",
+			annotations: map[string]string{aplAnnotationKey: "1:10.0.0.0/33"},
+			expectError: true,
+		},
+		{
+			title:       "family doesn't match the address",
+			annotations: map[string]string{aplAnnotationKey: "2:10.0.0.0/8"},
+			expectError: true,
+		},
+		{
+			title:       "empty record",
+			annotations: map[string]string{aplAnnotationKey: "1:10.0.0.0/8, "},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			targets, err := getRecordDataFromAnnotations(tc.annotations, aplAnnotationKey, endpoint.RecordTypeAPL, parseAPLData)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, targets)
+		})
+	}
+}
+
+func TestGetHINFODataFromAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		annotations map[string]string
+		expected    endpoint.Targets
+		expectError bool
+	}{
+		{
+			title:       "annotation not present",
+			annotations: map[string]string{"foo": "bar"},
+		},
+		{
+			title:       "unquoted values",
+			annotations: map[string]string{hinfoAnnotationKey: "amd64 linux"},
+			expected:    endpoint.Targets{`"amd64" "linux"`},
+		},
+		{
+			title:       "quoted values with whitespace",
+			annotations: map[string]string{hinfoAnnotationKey: `"Intel Xeon"   "Ubuntu 22.04"`},
+			expected:    endpoint.Targets{`"Intel Xeon" "Ubuntu 22.04"`},
+		},
+		{
+			title:       "mixed quoted and unquoted values",
+			annotations: map[string]string{hinfoAnnotationKey: `arm64 "Bottlerocket OS"`},
+			expected:    endpoint.Targets{`"arm64" "Bottlerocket OS"`},
+		},
+		{
+			title:       "non-ASCII and control characters are escaped as decimal bytes",
+			annotations: map[string]string{hinfoAnnotationKey: "\"Café CPU\" \"tab\there\""},
+			expected:    endpoint.Targets{`"Caf\195\169 CPU" "tab\009here"`},
+		},
+		{
+			title:       "backslashes are escaped",
+			annotations: map[string]string{hinfoAnnotationKey: `amd64 C:\\OS`},
+			expected:    endpoint.Targets{`"amd64" "C:\\\\OS"`},
+		},
+		{
+			title:       "commas within quoted values",
+			annotations: map[string]string{hinfoAnnotationKey: `"Intel, x86" "Linux", arm64 "Linux, ARM"`},
+			expected:    endpoint.Targets{`"Intel, x86" "Linux"`, `"arm64" "Linux, ARM"`},
+		},
+		{
+			title:       "missing os",
+			annotations: map[string]string{hinfoAnnotationKey: "amd64"},
+			expectError: true,
+		},
+		{
+			title:       "too many values",
+			annotations: map[string]string{hinfoAnnotationKey: "Intel Xeon Linux"},
+			expectError: true,
+		},
+		{
+			title:       "empty value",
+			annotations: map[string]string{hinfoAnnotationKey: `"" linux`},
+			expectError: true,
+		},
+		{
+			title:       "unterminated quote",
+			annotations: map[string]string{hinfoAnnotationKey: `"Intel Xeon linux`},
+			expectError: true,
+		},
+		{
+			title:       "quote within value",
+			annotations: map[string]string{hinfoAnnotationKey: `amd"64 linux`},
+			expectError: true,
+		},
+		{
+			title:       "value longer than 255 characters",
+			annotations: map[string]string{hinfoAnnotationKey: strings.Repeat("x", 256) + " linux"},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			targets, err := getRecordDataFromAnnotations(tc.annotations, hinfoAnnotationKey, endpoint.RecordTypeHINFO, parseHINFOData)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, targets)
+		})
+	}
+}
+
+func TestAnnotationRecordEndpoints(t *testing.T) {
+	annotations := map[string]string{
+		hinfoAnnotationKey: `"Intel, x86" "Linux"`,
+		dsAnnotationKey:    "not a ds record",
+	}
+
+	endpoints := annotationRecordEndpoints(annotations, "foo.example.org.", endpoint.TTL(60))
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeHINFO, RecordTTL: 60, Targets: endpoint.Targets{`"Intel, x86" "Linux"`}},
+	})
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// recordTypeFilterSource is a Source that removes endpoints whose record type isn't one of
+// the allowed types from its wrapped source.
+type recordTypeFilterSource struct {
+	source  Source
+	allowed map[string]bool
+}
+
+// NewRecordTypeFilterSource creates a new recordTypeFilterSource wrapping the provided Source.
+// Record types are matched case-insensitively and an empty list of allowed types keeps all endpoints.
+func NewRecordTypeFilterSource(source Source, allowed []string) Source {
+	types := make(map[string]bool, len(allowed))
+	for _, recordType := range allowed {
+		types[strings.ToUpper(recordType)] = true
+	}
+	return &recordTypeFilterSource{source: source, allowed: types}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns only those with an allowed record type.
+func (ms *recordTypeFilterSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ms.allowed) == 0 {
+		return endpoints, nil
+	}
+
+	result := []*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if !ms.allowed[strings.ToUpper(ep.RecordType)] {
+			log.Debugf("Dropping endpoint %s with filtered record type", ep)
+			continue
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *recordTypeFilterSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that recordTypeFilterSource is a Source
+var _ Source = &recordTypeFilterSource{}
+
+func TestRecordTypeFilterSource(t *testing.T) {
+	endpoints := func() []*endpoint.Endpoint {
+		return []*endpoint.Endpoint{
+			{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+			{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeAAAA, Targets: endpoint.Targets{"2001:db8::1"}},
+			{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"foo.example.org"}},
+			{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"\"heritage=external-dns\""}},
+		}
+	}
+
+	for _, tc := range []struct {
+		title    string
+		allowed  []string
+		expected []*endpoint.Endpoint
+	}{
+		{
+			"address records are kept",
+			[]string{"A", "AAAA"},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeAAAA, Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			"TXT records are kept",
+			[]string{"TXT"},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"\"heritage=external-dns\""}},
+			},
+		},
+		{
+			"record types are matched case-insensitively",
+			[]string{"cname", "aaaa"},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeAAAA, Targets: endpoint.Targets{"2001:db8::1"}},
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"foo.example.org"}},
+			},
+		},
+		{
+			"unknown record types drop everything",
+			[]string{"SRV"},
+			[]*endpoint.Endpoint{},
+		},
+		{
+			"empty allowed types pass everything",
+			nil,
+			endpoints(),
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(endpoints(), nil)
+
+			source := NewRecordTypeFilterSource(mockSource, tc.allowed)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
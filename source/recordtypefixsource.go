@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// recordTypeFixSource is a Source that detects address and CNAME records of its wrapped source
+// whose record type does not match the shape of their targets.
+type recordTypeFixSource struct {
+	source Source
+	fix    bool
+}
+
+// NewRecordTypeFixSource creates a new recordTypeFixSource wrapping the provided Source.
+// If fix is false, mismatching records are only logged and passed through unchanged.
+func NewRecordTypeFixSource(source Source, fix bool) Source {
+	return &recordTypeFixSource{source: source, fix: fix}
+}
+
+// Endpoints collects endpoints from its wrapped source and corrects the record type of A and AAAA
+// records whose single target is a hostname to CNAME, and of CNAME records whose targets are all
+// IP addresses to A or AAAA. Records with mixed targets are left untouched.
+func (ms *recordTypeFixSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		recordType := expectedRecordType(ep)
+		if recordType == ep.RecordType {
+			result = append(result, ep)
+			continue
+		}
+
+		if !ms.fix {
+			log.Warnf("Endpoint %s has record type %s but its targets require %s", ep, ep.RecordType, recordType)
+			result = append(result, ep)
+			continue
+		}
+
+		log.Infof("Changing record type of endpoint %s from %s to %s", ep, ep.RecordType, recordType)
+		ep = ep.DeepCopy()
+		ep.RecordType = recordType
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// expectedRecordType returns the record type matching the targets of an A, AAAA or CNAME endpoint.
+// It returns the current record type for other endpoints, endpoints with mixed targets and
+// endpoints with several hostname targets, as a CNAME record cannot have more than one target.
+func expectedRecordType(ep *endpoint.Endpoint) string {
+	switch ep.RecordType {
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME:
+	default:
+		return ep.RecordType
+	}
+	if len(ep.Targets) == 0 {
+		return ep.RecordType
+	}
+
+	var ipv4, ipv6, hostnames int
+	for _, t := range ep.Targets {
+		ip := net.ParseIP(t)
+		switch {
+		case ip == nil:
+			hostnames++
+		case ip.To4() != nil:
+			ipv4++
+		default:
+			ipv6++
+		}
+	}
+
+	switch len(ep.Targets) {
+	case hostnames:
+		// a CNAME record can only have a single target
+		if hostnames > 1 {
+			log.Warnf("Endpoint %s has record type %s but its targets are %d hostnames, which cannot form a CNAME record", ep, ep.RecordType, hostnames)
+			return ep.RecordType
+		}
+		return endpoint.RecordTypeCNAME
+	case ipv4:
+		return endpoint.RecordTypeA
+	case ipv6:
+		return endpoint.RecordTypeAAAA
+	}
+	return ep.RecordType
+}
+
+func (ms *recordTypeFixSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
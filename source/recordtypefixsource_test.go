@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that recordTypeFixSource is a Source
+var _ Source = &recordTypeFixSource{}
+
+func TestRecordTypeFixSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		fix       bool
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"A record with hostname target becomes CNAME",
+			true,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"lb.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.org"}},
+			},
+		},
+		{
+			"CNAME record with IPv4 targets becomes A",
+			true,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+			},
+		},
+		{
+			"CNAME record with IPv6 target becomes AAAA",
+			true,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			"mismatched records are kept when not fixing",
+			false,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"lb.example.org"}},
+				{DNSName: "bar.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"lb.example.org"}},
+				{DNSName: "bar.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			"A record with several hostname targets is left untouched",
+			true,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"lb1.example.org", "lb2.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"lb1.example.org", "lb2.example.org"}},
+			},
+		},
+		{
+			"mixed targets and other record types are left untouched",
+			true,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "lb.example.org"}},
+				{DNSName: "bar.example.org", RecordType: "TXT", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "baz.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "lb.example.org"}},
+				{DNSName: "bar.example.org", RecordType: "TXT", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "baz.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].RecordType
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewRecordTypeFixSource(mockSource, tc.fix)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].RecordType, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// RequireLabelInjectPrefix prefixes the value stamped on endpoints missing the required label.
+	RequireLabelInjectPrefix = "inject:"
+	// RequireLabelReject makes endpoints missing the required label be dropped.
+	RequireLabelReject = "reject"
+)
+
+// requiredLabelsSource is a Source that makes sure the endpoints of its wrapped source
+// carry the labels the registry relies on.
+type requiredLabelsSource struct {
+	source   Source
+	defaults map[string]string
+	reject   bool
+}
+
+// NewRequiredLabelsSource creates a new requiredLabelsSource wrapping the provided Source.
+// Each key of defaults is a required label, stamped with its default value on endpoints
+// missing it or carrying an empty value.
+func NewRequiredLabelsSource(source Source, defaults map[string]string) Source {
+	return &requiredLabelsSource{source: source, defaults: defaults}
+}
+
+// NewRequireLabelSource creates a new requiredLabelsSource wrapping the provided Source which
+// requires the label with the given key. With mode "inject:<value>" the value is stamped on
+// endpoints missing the label or carrying an empty value, with mode "reject" these endpoints
+// are dropped. Unknown modes are logged and reject.
+func NewRequireLabelSource(source Source, key, mode string) Source {
+	if strings.HasPrefix(mode, RequireLabelInjectPrefix) {
+		return NewRequiredLabelsSource(source, map[string]string{key: strings.TrimPrefix(mode, RequireLabelInjectPrefix)})
+	}
+	if mode != RequireLabelReject {
+		log.Errorf("Unknown mode %q for required label %s, rejecting endpoints without it", mode, key)
+	}
+	return &requiredLabelsSource{source: source, defaults: map[string]string{key: ""}, reject: true}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of them
+// with all required labels set. In reject mode, endpoints missing a required label
+// are dropped instead and the others are returned as they are.
+func (ms *requiredLabelsSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ms.reject {
+			if key, ok := ms.missingLabel(ep); ok {
+				log.Warnf("Dropping endpoint %s without required label %s", ep, key)
+				continue
+			}
+			result = append(result, ep)
+			continue
+		}
+
+		ep = ep.DeepCopy()
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		for key, value := range ms.defaults {
+			if ep.Labels[key] == "" {
+				ep.Labels[key] = value
+			}
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// missingLabel returns the first required label the endpoint misses or carries with an empty value.
+func (ms *requiredLabelsSource) missingLabel(ep *endpoint.Endpoint) (string, bool) {
+	for key := range ms.defaults {
+		if ep.Labels[key] == "" {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func (ms *requiredLabelsSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that requiredLabelsSource is a Source
+var _ Source = &requiredLabelsSource{}
+
+func TestRequiredLabelsSource(t *testing.T) {
+	defaults := map[string]string{
+		endpoint.OwnerLabelKey:    "default",
+		endpoint.ResourceLabelKey: "unknown",
+	}
+
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"nil labels receive all defaults",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.OwnerLabelKey:    "default",
+					endpoint.ResourceLabelKey: "unknown",
+				}},
+			},
+		},
+		{
+			"existing labels are kept",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.OwnerLabelKey: "cluster-a",
+					"foo":                  "bar",
+				}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.OwnerLabelKey:    "cluster-a",
+					endpoint.ResourceLabelKey: "unknown",
+					"foo":                     "bar",
+				}},
+			},
+		},
+		{
+			"empty labels receive defaults",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.OwnerLabelKey:    "",
+					endpoint.ResourceLabelKey: "node/node1",
+				}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{
+					endpoint.OwnerLabelKey:    "default",
+					endpoint.ResourceLabelKey: "node/node1",
+				}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewRequiredLabelsSource(mockSource, defaults)
+
+			endpoints, err := source.Endpoints(context.Background())
+			assert.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			for _, ep := range endpoints {
+				for key := range defaults {
+					assert.Contains(t, ep.Labels, key)
+				}
+			}
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequireLabelSource(t *testing.T) {
+	labeled := &endpoint.Endpoint{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "cluster-a"}}
+	unlabeled := &endpoint.Endpoint{DNSName: "bar.example.org", Targets: endpoint.Targets{"5.6.7.8"}}
+	empty := &endpoint.Endpoint{DNSName: "baz.example.org", Targets: endpoint.Targets{"9.9.9.9"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: ""}}
+
+	for _, tc := range []struct {
+		title    string
+		mode     string
+		expected []*endpoint.Endpoint
+	}{
+		{
+			"missing label is injected and existing label preserved",
+			"inject:cluster-b",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "cluster-a"}},
+				{DNSName: "bar.example.org", Targets: endpoint.Targets{"5.6.7.8"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "cluster-b"}},
+				{DNSName: "baz.example.org", Targets: endpoint.Targets{"9.9.9.9"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "cluster-b"}},
+			},
+		},
+		{
+			"endpoints without the label are rejected",
+			RequireLabelReject,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "cluster-a"}},
+			},
+		},
+		{
+			"unknown mode rejects",
+			"stamp",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "cluster-a"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return([]*endpoint.Endpoint{labeled, unlabeled, empty}, nil)
+
+			source := NewRequireLabelSource(mockSource, endpoint.OwnerLabelKey, tc.mode)
+
+			endpoints, err := source.Endpoints(context.Background())
+			assert.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			assert.Nil(t, unlabeled.Labels, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
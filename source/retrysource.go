@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// retrySource is a Source that retries failed calls to its wrapped source.
+type retrySource struct {
+	source    Source
+	attempts  int
+	baseDelay time.Duration
+}
+
+// NewRetrySource creates a new retrySource wrapping the provided Source.
+func NewRetrySource(source Source, attempts int, baseDelay time.Duration) Source {
+	return &retrySource{source: source, attempts: attempts, baseDelay: baseDelay}
+}
+
+// Endpoints collects endpoints from its wrapped source, calling it up to the configured number
+// of attempts while it fails. The delay between attempts starts at baseDelay and doubles after
+// each attempt. The error of the last attempt is returned if all attempts fail, the context
+// error if the context is done while waiting.
+func (ms *retrySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	delay := ms.baseDelay
+	for attempt := 1; ; attempt++ {
+		endpoints, err := ms.source.Endpoints(ctx)
+		if err == nil {
+			return endpoints, nil
+		}
+		if attempt >= ms.attempts {
+			return nil, err
+		}
+
+		log.Warnf("Failed to collect endpoints (attempt %d of %d), retrying in %s: %v", attempt, ms.attempts, delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (ms *retrySource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that retrySource is a Source
+var _ Source = &retrySource{}
+
+func TestRetrySource(t *testing.T) {
+	t.Run("Endpoints", testRetrySourceEndpoints)
+	t.Run("ContextCancellation", testRetrySourceContextCancellation)
+}
+
+// testRetrySourceEndpoints tests that failing calls are retried up to the configured attempts.
+func testRetrySourceEndpoints(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		failures    int
+		attempts    int
+		expectError bool
+	}{
+		{
+			title:    "success is returned right away",
+			attempts: 3,
+		},
+		{
+			title:    "success after failures is returned",
+			failures: 2,
+			attempts: 3,
+		},
+		{
+			title:       "last error is returned when all attempts fail",
+			failures:    3,
+			attempts:    3,
+			expectError: true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			expected := []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")}
+
+			mockSource := new(testutils.MockSource)
+			for i := 0; i < tc.failures; i++ {
+				mockSource.On("Endpoints").Return(nil, errors.New("transient")).Once()
+			}
+			if tc.failures < tc.attempts {
+				mockSource.On("Endpoints").Return(expected, nil).Once()
+			}
+
+			source := NewRetrySource(mockSource, tc.attempts, time.Millisecond)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if tc.expectError {
+				require.EqualError(t, err, "transient")
+			} else {
+				require.NoError(t, err)
+				validateEndpoints(t, endpoints, expected)
+			}
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+// testRetrySourceContextCancellation tests that a cancelled context aborts waiting for the next attempt.
+func testRetrySourceContextCancellation(t *testing.T) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(nil, errors.New("transient")).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	source := NewRetrySource(mockSource, 5, time.Hour)
+
+	start := time.Now()
+	_, err := source.Endpoints(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Minute)
+
+	mockSource.AssertNumberOfCalls(t, "Endpoints", 1)
+}
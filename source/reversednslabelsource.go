@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// ReverseDNSLabelKey is the name of the label holding the reverse DNS names of the targets of an endpoint.
+const ReverseDNSLabelKey = "reverse-dns"
+
+// ReverseResolver resolves addresses to names, as implemented by net.Resolver.
+type ReverseResolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// reverseDNSLabelSource is a Source that labels the A records of its wrapped source
+// with the reverse DNS names of their targets.
+type reverseDNSLabelSource struct {
+	source   Source
+	resolver ReverseResolver
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	cache      map[string]reverseDNSCacheEntry
+	cacheMutex sync.Mutex
+}
+
+// reverseDNSCacheEntry is a cached reverse lookup result.
+type reverseDNSCacheEntry struct {
+	name    string
+	expires time.Time
+}
+
+// NewReverseDNSLabelSource creates a new reverseDNSLabelSource wrapping the provided Source.
+// Lookup results, including failed ones, are cached for cacheTTL.
+func NewReverseDNSLabelSource(source Source, resolver ReverseResolver, cacheTTL time.Duration) Source {
+	return &reverseDNSLabelSource{
+		source:   source,
+		resolver: resolver,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    map[string]reverseDNSCacheEntry{},
+	}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of them with
+// A records labeled by the semicolon separated reverse DNS names of their targets.
+// Targets without reverse DNS name are left out of the label.
+func (ms *reverseDNSLabelSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA {
+			result = append(result, ep)
+			continue
+		}
+
+		names := []string{}
+		for _, t := range ep.Targets {
+			if name := ms.lookup(ctx, t); name != "" {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			result = append(result, ep)
+			continue
+		}
+
+		ep = ep.DeepCopy()
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		ep.Labels[ReverseDNSLabelKey] = strings.Join(names, ";")
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// lookup returns the first reverse DNS name of the address, or an empty string if there is none.
+// The lock is not held while resolving, so that a slow lookup doesn't block others.
+func (ms *reverseDNSLabelSource) lookup(ctx context.Context, addr string) string {
+	ms.cacheMutex.Lock()
+	entry, ok := ms.cache[addr]
+	ms.cacheMutex.Unlock()
+	if ok && ms.now().Before(entry.expires) {
+		return entry.name
+	}
+
+	name := ""
+	names, err := ms.resolver.LookupAddr(ctx, addr)
+	if err != nil {
+		log.Debugf("Failed to resolve reverse DNS name of %s: %v", addr, err)
+	} else if len(names) > 0 {
+		name = strings.TrimSuffix(names[0], ".")
+	}
+
+	ms.cacheMutex.Lock()
+	defer ms.cacheMutex.Unlock()
+
+	ms.cache[addr] = reverseDNSCacheEntry{name: name, expires: ms.now().Add(ms.cacheTTL)}
+	return name
+}
+
+func (ms *reverseDNSLabelSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
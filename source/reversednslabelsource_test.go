@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that reverseDNSLabelSource is a Source
+var _ Source = &reverseDNSLabelSource{}
+
+// fakeReverseResolver resolves addresses from a fixed table and counts lookups.
+type fakeReverseResolver struct {
+	names   map[string][]string
+	lookups int
+}
+
+func (r *fakeReverseResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	r.lookups++
+	names, ok := r.names[addr]
+	if !ok {
+		return nil, fmt.Errorf("no PTR record for %s", addr)
+	}
+	return names, nil
+}
+
+func TestReverseDNSLabelSource(t *testing.T) {
+	t.Run("Endpoints", testReverseDNSLabelSourceEndpoints)
+	t.Run("Cache", testReverseDNSLabelSourceCache)
+	t.Run("SlowLookup", testReverseDNSLabelSourceSlowLookup)
+}
+
+// testReverseDNSLabelSourceEndpoints tests that A records are labeled with their PTR names.
+func testReverseDNSLabelSourceEndpoints(t *testing.T) {
+	resolver := &fakeReverseResolver{names: map[string][]string{
+		"1.1.1.1": {"one.example.org."},
+		"2.2.2.2": {"two.example.org.", "other.example.org."},
+	}}
+
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"targets are labeled with their PTR names",
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}, Labels: endpoint.Labels{
+					ReverseDNSLabelKey: "one.example.org;two.example.org",
+				}},
+			},
+		},
+		{
+			"targets without PTR names are left out",
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3", "1.1.1.1"}, Labels: endpoint.Labels{"foo": "bar"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3", "1.1.1.1"}, Labels: endpoint.Labels{
+					"foo":              "bar",
+					ReverseDNSLabelKey: "one.example.org",
+				}},
+			},
+		},
+		{
+			"endpoints without any PTR names are not labeled",
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}},
+			},
+		},
+		{
+			"non A records are not labeled",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"1.1.1.1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"1.1.1.1"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewReverseDNSLabelSource(mockSource, resolver, time.Minute)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			assert.NotContains(t, tc.endpoints[0].Labels, ReverseDNSLabelKey, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+// testReverseDNSLabelSourceCache tests that lookups are cached until they expire.
+func testReverseDNSLabelSourceCache(t *testing.T) {
+	resolver := &fakeReverseResolver{names: map[string][]string{"1.1.1.1": {"one.example.org."}}}
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "nodes.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "3.3.3.3"}},
+	}, nil)
+
+	now := time.Now()
+	source := NewReverseDNSLabelSource(mockSource, resolver, time.Minute).(*reverseDNSLabelSource)
+	source.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := source.Endpoints(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, resolver.lookups, "hits and misses must be cached")
+
+	now = now.Add(2 * time.Minute)
+	_, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, resolver.lookups, "expired entries must be resolved again")
+}
+
+// blockingReverseResolver blocks lookups of an address until released.
+type blockingReverseResolver struct {
+	blocked string
+	release chan struct{}
+}
+
+func (r *blockingReverseResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	if addr == r.blocked {
+		<-r.release
+	}
+	return []string{"host-" + addr + "."}, nil
+}
+
+// testReverseDNSLabelSourceSlowLookup tests that a slow lookup doesn't block lookups of other addresses.
+func testReverseDNSLabelSourceSlowLookup(t *testing.T) {
+	resolver := &blockingReverseResolver{blocked: "1.1.1.1", release: make(chan struct{})}
+	defer close(resolver.release)
+	source := NewReverseDNSLabelSource(new(testutils.MockSource), resolver, time.Minute).(*reverseDNSLabelSource)
+
+	go source.lookup(context.Background(), "1.1.1.1")
+
+	done := make(chan string)
+	go func() { done <- source.lookup(context.Background(), "2.2.2.2") }()
+	select {
+	case name := <-done:
+		assert.Equal(t, "host-2.2.2.2", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookup of 2.2.2.2 blocked by 1.1.1.1")
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// serializableSource is a Source that checks that the endpoints of its wrapped source
+// survive the serialization of the registry.
+type serializableSource struct {
+	source Source
+	strict bool
+}
+
+// NewSerializableSource creates a new serializableSource wrapping the provided Source
+// which drops endpoints that can't be serialized.
+func NewSerializableSource(source Source) Source {
+	return &serializableSource{source: source}
+}
+
+// NewStrictSerializableSource creates a new serializableSource wrapping the provided Source
+// which fails on endpoints that can't be serialized.
+func NewStrictSerializableSource(source Source) Source {
+	return &serializableSource{source: source, strict: true}
+}
+
+// checkSerializable checks that the labels of the endpoint are restored unchanged from their
+// serialization into TXT registry records, and that its provider specific properties are named.
+func checkSerializable(ep *endpoint.Endpoint) error {
+	if len(ep.Labels) > 0 {
+		restored, err := endpoint.NewLabelsFromString(ep.Labels.Serialize(true))
+		if err != nil {
+			return fmt.Errorf("labels %v can't be serialized: %w", ep.Labels, err)
+		}
+		if !reflect.DeepEqual(restored, ep.Labels) {
+			return fmt.Errorf("labels %v are restored as %v", ep.Labels, restored)
+		}
+	}
+	for _, property := range ep.ProviderSpecific {
+		if property.Name == "" {
+			return fmt.Errorf("provider specific property with value %q has no name", property.Value)
+		}
+	}
+	return nil
+}
+
+// Endpoints collects endpoints from its wrapped source and checks that they can be serialized.
+// Endpoints failing the check are dropped, or fail the call in strict mode.
+func (ms *serializableSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if err := checkSerializable(ep); err != nil {
+			if ms.strict {
+				return nil, fmt.Errorf("endpoint %s: %w", ep.DNSName, err)
+			}
+			log.Warnf("Dropping endpoint %s: %v", ep.DNSName, err)
+			continue
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *serializableSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
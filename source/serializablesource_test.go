@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that serializableSource is a Source
+var _ Source = &serializableSource{}
+
+func TestCheckSerializable(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		endpoint    *endpoint.Endpoint
+		expectError string
+	}{
+		{
+			"endpoint without labels",
+			&endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			"",
+		},
+		{
+			"endpoint with labels and properties",
+			&endpoint.Endpoint{
+				DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"},
+				Labels:           endpoint.Labels{endpoint.OwnerLabelKey: "default", endpoint.ResourceLabelKey: "node/foo"},
+				ProviderSpecific: endpoint.ProviderSpecific{{Name: "alias", Value: "true"}},
+			},
+			"",
+		},
+		{
+			"label value with a separator",
+			&endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"zones": "a,b"}},
+			"are restored as",
+		},
+		{
+			"label key with an equal sign",
+			&endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"a=b": "c"}},
+			"are restored as",
+		},
+		{
+			"unnamed provider specific property",
+			&endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, ProviderSpecific: endpoint.ProviderSpecific{{Value: "true"}}},
+			`provider specific property with value "true" has no name`,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			err := checkSerializable(tc.endpoint)
+			if tc.expectError == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
+
+func TestSerializableSource(t *testing.T) {
+	valid := &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"foo": "bar"}}
+	unserializable := &endpoint.Endpoint{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}, Labels: endpoint.Labels{"foo": "bar,baz"}}
+
+	for _, tc := range []struct {
+		title       string
+		constructor func(Source) Source
+		expected    []*endpoint.Endpoint
+		expectError string
+	}{
+		{
+			"unserializable endpoints are dropped",
+			NewSerializableSource,
+			[]*endpoint.Endpoint{valid},
+			"",
+		},
+		{
+			"unserializable endpoints fail when strict",
+			NewStrictSerializableSource,
+			nil,
+			"endpoint bar.example.org: labels",
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return([]*endpoint.Endpoint{valid, unserializable}, nil)
+
+			source := tc.constructor(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if tc.expectError != "" {
+				require.ErrorContains(t, err, tc.expectError)
+			} else {
+				require.NoError(t, err)
+				validateEndpoints(t, endpoints, tc.expected)
+			}
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
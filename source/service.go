@@ -378,14 +378,21 @@ func (sc *serviceSource) endpoints(svc *v1.Service) []*endpoint.Endpoint {
 		var hostnameList []string
 		var internalHostnameList []string
 
+		ttl, err := getTTLFromAnnotations(svc.Annotations)
+		if err != nil {
+			log.Warn(err)
+		}
+
 		hostnameList = getHostnamesFromAnnotations(svc.Annotations)
 		for _, hostname := range hostnameList {
 			endpoints = append(endpoints, sc.generateEndpoints(svc, hostname, providerSpecific, setIdentifier, false)...)
+			endpoints = append(endpoints, annotationRecordEndpoints(svc.Annotations, hostname, ttl)...)
 		}
 
 		internalHostnameList = getInternalHostnamesFromAnnotations(svc.Annotations)
 		for _, hostname := range internalHostnameList {
 			endpoints = append(endpoints, sc.generateEndpoints(svc, hostname, providerSpecific, setIdentifier, true)...)
+			endpoints = append(endpoints, annotationRecordEndpoints(svc.Annotations, hostname, ttl)...)
 		}
 	}
 	return endpoints
@@ -1307,6 +1307,21 @@ func TestClusterIpServices(t *testing.T) {
 				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
 			},
 		},
+		{
+			title:        "annotated ClusterIp services return record data annotations",
+			svcNamespace: "testing",
+			svcName:      "foo",
+			svcType:      v1.ServiceTypeClusterIP,
+			annotations: map[string]string{
+				hostnameAnnotationKey: "foo.example.org.",
+				hinfoAnnotationKey:    `"Intel, x86" "Linux"`,
+			},
+			clusterIP: "1.2.3.4",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeHINFO, Targets: endpoint.Targets{`"Intel, x86" "Linux"`}},
+			},
+		},
 		{
 			title:                    "hostname annotated ClusterIp services are ignored",
 			svcNamespace:             "testing",
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sort"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// setIdentifierOrderSource is a Source that returns the endpoints of its wrapped source
+// in a deterministic order.
+type setIdentifierOrderSource struct {
+	source Source
+}
+
+// NewSetIdentifierOrderSource creates a new setIdentifierOrderSource wrapping the provided Source.
+func NewSetIdentifierOrderSource(source Source) Source {
+	return &setIdentifierOrderSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them ordered by DNS name,
+// record type and set identifier, so that the members of a set identifier group are always
+// adjacent and in the same order regardless of the order of the wrapped source.
+func (ms *setIdentifierOrderSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, len(endpoints))
+	copy(result, endpoints)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.DNSName != b.DNSName {
+			return a.DNSName < b.DNSName
+		}
+		if a.RecordType != b.RecordType {
+			return a.RecordType < b.RecordType
+		}
+		return a.SetIdentifier < b.SetIdentifier
+	})
+
+	return result, nil
+}
+
+func (ms *setIdentifierOrderSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
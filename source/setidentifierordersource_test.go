@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that setIdentifierOrderSource is a Source
+var _ Source = &setIdentifierOrderSource{}
+
+func TestSetIdentifierOrderSource(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"2.2.2.2"}},
+		{DNSName: "bar.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"3.3.3.3"}},
+		{DNSName: "foo.example.org", RecordType: "TXT", SetIdentifier: "a", Targets: endpoint.Targets{"text"}},
+		{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "c", Targets: endpoint.Targets{"4.4.4.4"}},
+		{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.1.1.1"}},
+	}
+	expected := []string{
+		"bar.example.org/A/a",
+		"foo.example.org/A/a",
+		"foo.example.org/A/b",
+		"foo.example.org/A/c",
+		"foo.example.org/TXT/a",
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for run := 0; run < 10; run++ {
+		shuffled := make([]*endpoint.Endpoint, len(endpoints))
+		copy(shuffled, endpoints)
+		rnd.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		mockSource := new(testutils.MockSource)
+		mockSource.On("Endpoints").Return(shuffled, nil)
+
+		result, err := NewSetIdentifierOrderSource(mockSource).Endpoints(context.Background())
+		require.NoError(t, err)
+
+		order := make([]string, 0, len(result))
+		for _, ep := range result {
+			order = append(order, ep.DNSName+"/"+ep.RecordType+"/"+ep.SetIdentifier)
+		}
+		assert.Equal(t, expected, order)
+
+		mockSource.AssertExpectations(t)
+	}
+}
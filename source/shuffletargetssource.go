@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// shuffleTargetsSource is a Source that randomizes the order of the targets of the endpoints of
+// its wrapped source on every call, so that providers keeping the order don't pin clients to the
+// first target.
+type shuffleTargetsSource struct {
+	source Source
+	seed   func() int64
+}
+
+// NewShuffleTargetsSource creates a new shuffleTargetsSource wrapping the provided Source.
+// The shuffle of each call is seeded by the given function, which defaults to the current time.
+func NewShuffleTargetsSource(source Source, seed func() int64) Source {
+	if seed == nil {
+		seed = func() int64 { return time.Now().UnixNano() }
+	}
+	return &shuffleTargetsSource{source: source, seed: seed}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of them with their
+// targets shuffled. Targets are only reordered, never added or removed.
+func (ms *shuffleTargetsSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := rand.New(rand.NewSource(ms.seed()))
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		ep = ep.DeepCopy()
+		r.Shuffle(len(ep.Targets), func(i, j int) {
+			ep.Targets[i], ep.Targets[j] = ep.Targets[j], ep.Targets[i]
+		})
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *shuffleTargetsSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that shuffleTargetsSource is a Source
+var _ Source = &shuffleTargetsSource{}
+
+func TestShuffleTargetsSource(t *testing.T) {
+	t.Run("Preserved", testShuffleTargetsSourcePreserved)
+	t.Run("Deterministic", testShuffleTargetsSourceDeterministic)
+}
+
+func newShuffleTestEndpoints() []*endpoint.Endpoint {
+	return []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4", "5.5.5.5", "6.6.6.6", "7.7.7.7", "8.8.8.8"}},
+		{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.1", "2.2.2.2"}},
+		{DNSName: "baz.example.org", RecordType: "A", Targets: endpoint.Targets{}},
+	}
+}
+
+// testShuffleTargetsSourcePreserved tests that targets are only reordered and wrapped endpoints are not modified.
+func testShuffleTargetsSourcePreserved(t *testing.T) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(newShuffleTestEndpoints(), nil)
+
+	source := NewShuffleTargetsSource(mockSource, nil)
+
+	for i := 0; i < 10; i++ {
+		endpoints, err := source.Endpoints(context.Background())
+		require.NoError(t, err)
+		require.Len(t, endpoints, 3)
+
+		for j, expected := range newShuffleTestEndpoints() {
+			assert.Equal(t, expected.DNSName, endpoints[j].DNSName)
+			assert.ElementsMatch(t, expected.Targets, endpoints[j].Targets)
+		}
+	}
+
+	wrapped, err := mockSource.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, newShuffleTestEndpoints(), wrapped, "wrapped endpoints must not be modified")
+
+	mockSource.AssertExpectations(t)
+}
+
+// testShuffleTargetsSourceDeterministic tests that a fixed seed yields the same order.
+func testShuffleTargetsSourceDeterministic(t *testing.T) {
+	shuffled := func(seed int64) endpoint.Targets {
+		mockSource := new(testutils.MockSource)
+		mockSource.On("Endpoints").Return(newShuffleTestEndpoints(), nil)
+
+		endpoints, err := NewShuffleTargetsSource(mockSource, func() int64 { return seed }).Endpoints(context.Background())
+		require.NoError(t, err)
+		return endpoints[0].Targets
+	}
+
+	assert.Equal(t, shuffled(42), shuffled(42))
+	assert.NotEqual(t, newShuffleTestEndpoints()[0].Targets, shuffled(42), "targets must be reordered")
+}
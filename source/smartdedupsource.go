@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// smartDedupSource is a Source that merges endpoints of its wrapped source which share
+// the same DNS name, record type and set identifier.
+type smartDedupSource struct {
+	source    Source
+	normalize func(dnsName string) string
+	keepFirst bool
+}
+
+// NewSmartDedupSource creates a new smartDedupSource wrapping the provided Source.
+func NewSmartDedupSource(source Source) Source {
+	return &smartDedupSource{source: source}
+}
+
+// NewCaseInsensitiveDedupSource creates a new smartDedupSource wrapping the provided Source,
+// which lowercases DNS names and strips their trailing dot before merging, so that names
+// differing only in case are merged as well.
+func NewCaseInsensitiveDedupSource(source Source) Source {
+	return &smartDedupSource{source: source, normalize: normalizeDNSNameCase}
+}
+
+// NewTargetMergeDedupSource creates a new smartDedupSource wrapping the provided Source,
+// which only unites the targets of duplicates and otherwise keeps the first endpoint as is.
+func NewTargetMergeDedupSource(source Source) Source {
+	return &smartDedupSource{source: source, keepFirst: true}
+}
+
+// normalizeDNSNameCase returns the lowercase DNS name without trailing dot.
+func normalizeDNSNameCase(dnsName string) string {
+	return strings.ToLower(strings.TrimSuffix(dnsName, "."))
+}
+
+// Endpoints collects endpoints from its wrapped source and merges duplicates into a single
+// endpoint: targets and labels are united, provider specific properties are united with the
+// first value winning on conflict and the highest TTL is kept. If keepFirst is set, only the
+// targets are united and everything else is taken from the first endpoint.
+func (ms *smartDedupSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*endpoint.Endpoint{}
+	collected := map[string]*endpoint.Endpoint{}
+
+	for _, ep := range endpoints {
+		dnsName := ep.DNSName
+		if ms.normalize != nil {
+			dnsName = ms.normalize(dnsName)
+		}
+		identifier := dnsName + " / " + ep.RecordType + " / " + ep.SetIdentifier
+		merged, ok := collected[identifier]
+		if !ok {
+			merged = ep.DeepCopy()
+			merged.DNSName = dnsName
+			merged.Targets = endpoint.Targets{}
+			collected[identifier] = merged
+			result = append(result, merged)
+		} else {
+			log.Debugf("Merging duplicate endpoint %s", ep)
+		}
+		if ms.keepFirst {
+			mergeTargetsInto(merged, ep)
+		} else {
+			mergeInto(merged, ep)
+		}
+	}
+
+	return result, nil
+}
+
+// mergeInto merges the targets, labels, provider specific properties and TTL of ep into merged.
+func mergeInto(merged, ep *endpoint.Endpoint) {
+	merged.Targets = append(merged.Targets, ep.Targets...).Dedup()
+
+	if len(ep.Labels) > 0 && merged.Labels == nil {
+		merged.Labels = endpoint.NewLabels()
+	}
+	for k, v := range ep.Labels {
+		if _, ok := merged.Labels[k]; !ok {
+			merged.Labels[k] = v
+		}
+	}
+
+	for _, p := range ep.ProviderSpecific {
+		existing, ok := merged.GetProviderSpecificProperty(p.Name)
+		if !ok {
+			merged.ProviderSpecific = append(merged.ProviderSpecific, p)
+		} else if existing.Value != p.Value {
+			log.Warnf("Conflicting provider specific property %s for %s: keeping %q, ignoring %q", p.Name, merged.DNSName, existing.Value, p.Value)
+		}
+	}
+
+	if ep.RecordTTL > merged.RecordTTL {
+		merged.RecordTTL = ep.RecordTTL
+	}
+}
+
+// mergeTargetsInto merges the targets of ep into merged, warning if their TTLs disagree.
+func mergeTargetsInto(merged, ep *endpoint.Endpoint) {
+	merged.Targets = append(merged.Targets, ep.Targets...).Dedup()
+
+	if ep.RecordTTL != merged.RecordTTL {
+		log.Warnf("Conflicting TTL for %s %s: keeping %d, ignoring %d", merged.DNSName, merged.RecordType, merged.RecordTTL, ep.RecordTTL)
+	}
+}
+
+func (ms *smartDedupSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
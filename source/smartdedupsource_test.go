@@ -0,0 +1,329 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that smartDedupSource is a Source
+var _ Source = &smartDedupSource{}
+
+func TestSmartDedup(t *testing.T) {
+	t.Run("Endpoints", testSmartDedupEndpoints)
+	t.Run("DoesNotMutate", testSmartDedupDoesNotMutate)
+	t.Run("CaseInsensitive", testSmartDedupCaseInsensitive)
+	t.Run("TargetMerge", testSmartDedupTargetMerge)
+	t.Run("TargetMergeTTLConflict", testSmartDedupTargetMergeTTLConflict)
+}
+
+// testSmartDedupEndpoints tests that duplicates from the wrapped source are merged.
+func testSmartDedupEndpoints(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"different endpoints are kept",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			"different record types are not merged",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"text"}},
+			},
+		},
+		{
+			"different set identifiers are not merged",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "a", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "A", SetIdentifier: "b", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+		},
+		{
+			"targets are united",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8", "9.10.11.12"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8", "9.10.11.12"}},
+			},
+		},
+		{
+			"labels are united keeping the first value",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"owner": "a", "foo": "bar"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"owner": "b", "baz": "qux"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"owner": "a", "foo": "bar", "baz": "qux"}},
+			},
+		},
+		{
+			"labels are merged into nil labels",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"owner": "b"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"owner": "b"}},
+			},
+		},
+		{
+			"provider specific properties are united with the first winning on conflict",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: CloudflareProxiedKey, Value: "true"},
+				}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: CloudflareProxiedKey, Value: "false"},
+					{Name: "alias", Value: "true"},
+				}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: CloudflareProxiedKey, Value: "true"},
+					{Name: "alias", Value: "true"},
+				}},
+			},
+		},
+		{
+			"highest TTL is kept",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 60},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 300},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 300},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			// Create our object under test and get the endpoints.
+			source := NewSmartDedupSource(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Validate returned endpoints against desired endpoints.
+			validateEndpoints(t, endpoints, tc.expected)
+
+			// Validate that the mock source was called.
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+// testSmartDedupDoesNotMutate tests that the endpoints of the wrapped source are left untouched.
+func testSmartDedupDoesNotMutate(t *testing.T) {
+	original := []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"owner": "a"}},
+		{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}, Labels: endpoint.Labels{"foo": "bar"}},
+	}
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(original, nil)
+
+	_, err := NewSmartDedupSource(mockSource).Endpoints(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validateEndpoint(t, original[0], &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{"owner": "a"}})
+}
+
+// testSmartDedupCaseInsensitive tests that names differing only in case are merged.
+func testSmartDedupCaseInsensitive(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"case differing duplicates are merged",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "FOO.Example.ORG", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+			},
+		},
+		{
+			"trailing dot duplicates are merged",
+			[]*endpoint.Endpoint{
+				{DNSName: "EXAMPLE.ORG.", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			"different names are kept",
+			[]*endpoint.Endpoint{
+				{DNSName: "FOO.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewCaseInsensitiveDedupSource(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			validateEndpoints(t, endpoints, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+// testSmartDedupTargetMerge tests that only targets are united when keeping the first endpoint.
+func testSmartDedupTargetMerge(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"targets are united in order without duplicates",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8", "9.9.9.9"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8", "9.9.9.9"}},
+			},
+		},
+		{
+			"labels and provider specific properties of the first endpoint are kept",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 60,
+					Labels: endpoint.Labels{"foo": "bar"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: "alias", Value: "false"}}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}, RecordTTL: 300,
+					Labels: endpoint.Labels{"foo": "baz", "bar": "qux"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: "alias", Value: "true"}, {Name: "weight", Value: "1"}}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}, RecordTTL: 60,
+					Labels: endpoint.Labels{"foo": "bar"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: "alias", Value: "false"}}},
+			},
+		},
+		{
+			"different record types are never merged",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"bar.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"bar.example.org"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewTargetMergeDedupSource(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := endpoints[0].Targets.String(), tc.expected[0].Targets.String(); got != want {
+				t.Errorf("expected targets %s in order, got %s", want, got)
+			}
+			validateEndpoints(t, endpoints, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+// testSmartDedupTargetMergeTTLConflict tests that disagreeing TTLs are logged and the first one is kept.
+func testSmartDedupTargetMergeTTLConflict(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "ttl.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 60},
+		{DNSName: "ttl.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}, RecordTTL: 300},
+	}, nil)
+
+	endpoints, err := NewTargetMergeDedupSource(mockSource).Endpoints(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{DNSName: "ttl.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}, RecordTTL: 60},
+	})
+
+	logged := false
+	for _, entry := range hook.AllEntries() {
+		if strings.Contains(entry.Message, "Conflicting TTL for ttl.example.org A") {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Error("expected TTL conflict to be logged")
+	}
+}
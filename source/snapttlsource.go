@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// snapTTLSource is a Source that snaps the TTLs of its wrapped source to
+// the discrete values accepted by a provider.
+type snapTTLSource struct {
+	source  Source
+	allowed []int
+}
+
+// NewSnapTTLSource creates a new snapTTLSource wrapping the provided Source.
+func NewSnapTTLSource(source Source, allowed []int) Source {
+	return &snapTTLSource{source: source, allowed: allowed}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them with each configured
+// TTL replaced by the nearest allowed value, preferring the higher value on ties.
+// Unconfigured TTLs are left alone.
+func (ms *snapTTLSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ms.allowed) == 0 {
+		return endpoints, nil
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordTTL.IsConfigured() {
+			if snapped := ms.snap(ep.RecordTTL); snapped != ep.RecordTTL {
+				log.Debugf("Snapping TTL of %s from %d to %d", ep.DNSName, ep.RecordTTL, snapped)
+				ep = ep.DeepCopy()
+				ep.RecordTTL = snapped
+			}
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// snap returns the allowed value nearest to ttl.
+func (ms *snapTTLSource) snap(ttl endpoint.TTL) endpoint.TTL {
+	best := endpoint.TTL(ms.allowed[0])
+	for _, a := range ms.allowed[1:] {
+		candidate := endpoint.TTL(a)
+		d, bestD := distance(candidate, ttl), distance(best, ttl)
+		if d < bestD || (d == bestD && candidate > best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// distance returns the absolute difference between two TTLs.
+func distance(a, b endpoint.TTL) endpoint.TTL {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func (ms *snapTTLSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that snapTTLSource is a Source
+var _ Source = &snapTTLSource{}
+
+func TestSnapTTLSource(t *testing.T) {
+	allowed := []int{60, 300, 600, 1800}
+
+	for _, tc := range []struct {
+		title    string
+		ttl      endpoint.TTL
+		allowed  []int
+		expected endpoint.TTL
+	}{
+		{"unconfigured TTL is kept", 0, allowed, 0},
+		{"allowed TTL is kept", 300, allowed, 300},
+		{"low TTL snaps to the lowest value", 1, allowed, 60},
+		{"high TTL snaps to the highest value", 86400, allowed, 1800},
+		{"TTL snaps to the nearest lower value", 400, allowed, 300},
+		{"TTL snaps to the nearest higher value", 500, allowed, 600},
+		{"tie snaps to the higher value", 450, allowed, 600},
+		{"unsorted allowed values", 100, []int{1800, 60, 600, 300}, 60},
+		{"no allowed values keep TTL", 123, nil, 123},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: tc.ttl}
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return([]*endpoint.Endpoint{original}, nil)
+
+			source := NewSnapTTLSource(mockSource, tc.allowed)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+			require.Len(t, endpoints, 1)
+
+			assert.Equal(t, tc.expected, endpoints[0].RecordTTL)
+			assert.Equal(t, tc.ttl, original.RecordTTL, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -59,6 +59,20 @@ const (
 	controllerAnnotationValue = "dns-controller"
 	// The annotation used for defining the desired hostname
 	internalHostnameAnnotationKey = "external-dns.alpha.kubernetes.io/internal-hostname"
+	// The annotation used for defining the desired CERT record data
+	certAnnotationKey = "external-dns.alpha.kubernetes.io/cert"
+	// The annotation used for defining the desired DS record data
+	dsAnnotationKey = "external-dns.alpha.kubernetes.io/ds"
+	// The annotation used for defining the desired OPENPGPKEY record data
+	openPGPKeyAnnotationKey = "external-dns.alpha.kubernetes.io/openpgpkey"
+	// The annotation used for defining the desired APL record data
+	aplAnnotationKey = "external-dns.alpha.kubernetes.io/apl"
+	// The annotation used for defining the desired HINFO record data
+	hinfoAnnotationKey = "external-dns.alpha.kubernetes.io/hinfo"
+	// The annotation used for defining the firewall tags associated with the records
+	firewallTagsAnnotationKey = "external-dns.alpha.kubernetes.io/firewall-tags"
+	// The annotation used for defining the anycast addresses announced by a node
+	anycastAnnotationKey = "external-dns.alpha.kubernetes.io/anycast-addresses"
 )
 
 const (
@@ -141,11 +155,27 @@ func parseTemplate(fqdnTemplate string) (tmpl *template.Template, err error) {
 		return nil, nil
 	}
 	funcs := template.FuncMap{
+		// trimPrefix takes its arguments in the order of strings.TrimPrefix for compatibility
+		// with existing templates, all other functions take the piped value last.
 		"trimPrefix": strings.TrimPrefix,
+		"trimSuffix": trimSuffix,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"replace":    replace,
 	}
 	return template.New("endpoint").Funcs(funcs).Parse(fqdnTemplate)
 }
 
+// trimSuffix returns s without the given suffix, e.g. {{ .Name | trimSuffix ".internal" }}.
+func trimSuffix(suffix, s string) string {
+	return strings.TrimSuffix(s, suffix)
+}
+
+// replace returns s with all occurrences of oldValue replaced by newValue, e.g. {{ .Name | replace "." "-" }}.
+func replace(oldValue, newValue, s string) string {
+	return strings.ReplaceAll(s, oldValue, newValue)
+}
+
 func getHostnamesFromAnnotations(annotations map[string]string) []string {
 	hostnameAnnotation, exists := annotations[hostnameAnnotationKey]
 	if !exists {
@@ -221,10 +251,16 @@ func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.Pro
 // getTargetsFromTargetAnnotation gets endpoints from optional "target" annotation.
 // Returns empty endpoints array if none are found.
 func getTargetsFromTargetAnnotation(annotations map[string]string) endpoint.Targets {
+	return getTargetsFromAnnotation(annotations, targetAnnotationKey)
+}
+
+// getTargetsFromAnnotation gets endpoints from the optional annotation with the given key.
+// Returns empty endpoints array if none are found.
+func getTargetsFromAnnotation(annotations map[string]string, key string) endpoint.Targets {
 	var targets endpoint.Targets
 
 	// Get the desired hostname of the ingress from the annotation.
-	targetAnnotation, exists := annotations[targetAnnotationKey]
+	targetAnnotation, exists := annotations[key]
 	if exists && targetAnnotation != "" {
 		// splits the hostname annotation and removes the trailing periods
 		targetsList := strings.Split(strings.Replace(targetAnnotation, " ", "", -1), ",")
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// splitHorizonSource is a Source that places each endpoint of its wrapped source either in the
+// internal or in the external zone of a split-horizon setup.
+type splitHorizonSource struct {
+	source       Source
+	internalZone string
+	externalZone string
+	internal     func(ep *endpoint.Endpoint) bool
+}
+
+// NewSplitHorizonSource creates a new splitHorizonSource wrapping the provided Source.
+// The internal function returns true for endpoints belonging to the internal view.
+func NewSplitHorizonSource(source Source, internalZone, externalZone string, internal func(ep *endpoint.Endpoint) bool) Source {
+	return &splitHorizonSource{source: source, internalZone: internalZone, externalZone: externalZone, internal: internal}
+}
+
+// Endpoints collects endpoints from its wrapped source and moves those of either zone into
+// the internal zone if they belong to the internal view and into the external zone otherwise,
+// by replacing the zone suffix of their DNS names. If one zone is nested in the other, names
+// belong to the more specific one. Endpoints outside both zones are passed through.
+func (ms *splitHorizonSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		from, to := ms.zoneOf(ep.DNSName), ms.externalZone
+		if ms.internal(ep) {
+			to = ms.internalZone
+		}
+		if from != "" && from != to {
+			dnsName, _ := swapDNSNameSuffix(ep.DNSName, from, to)
+			log.Debugf("Moving endpoint %s into zone %s", ep, to)
+			ep = ep.DeepCopy()
+			ep.DNSName = dnsName
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// zoneOf returns the most specific of both zones containing the DNS name, or "" if none does.
+func (ms *splitHorizonSource) zoneOf(dnsName string) string {
+	zone := ""
+	for _, z := range []string{ms.internalZone, ms.externalZone} {
+		if _, ok := swapDNSNameSuffix(dnsName, z, z); ok && len(z) > len(zone) {
+			zone = z
+		}
+	}
+	return zone
+}
+
+func (ms *splitHorizonSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that splitHorizonSource is a Source
+var _ Source = &splitHorizonSource{}
+
+func TestSplitHorizonSource(t *testing.T) {
+	// private endpoints belong to the internal view
+	private := func(ep *endpoint.Endpoint) bool {
+		for _, t := range ep.Targets {
+			if addr, err := netip.ParseAddr(t); err != nil || !addr.IsPrivate() {
+				return false
+			}
+		}
+		return len(ep.Targets) > 0
+	}
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "db.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1"}},
+		{DNSName: "web.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+		{DNSName: "cache.internal.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.2"}},
+		{DNSName: "api.internal.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+		{DNSName: "internal.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.3"}},
+		{DNSName: "foo.example.com", RecordType: "A", Targets: endpoint.Targets{"10.0.0.4"}},
+	}, nil)
+
+	source := NewSplitHorizonSource(mockSource, "internal.example.org", "example.org", private)
+
+	endpoints, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{DNSName: "db.internal.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1"}},
+		{DNSName: "web.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+		{DNSName: "cache.internal.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.2"}},
+		{DNSName: "api.example.org", RecordType: "A", Targets: endpoint.Targets{"5.6.7.8"}},
+		{DNSName: "internal.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.3"}},
+		{DNSName: "foo.example.com", RecordType: "A", Targets: endpoint.Targets{"10.0.0.4"}},
+	})
+
+	mockSource.AssertExpectations(t)
+}
+
+func TestSplitHorizonSourceDisjointZones(t *testing.T) {
+	internal := func(ep *endpoint.Endpoint) bool { return ep.Labels["view"] == "internal" }
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "db.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1"}, Labels: endpoint.Labels{"view": "internal"}},
+		{DNSName: "web.corp.local", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+		{DNSName: "app.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"web.example.org"}},
+	}, nil)
+
+	source := NewSplitHorizonSource(mockSource, "corp.local", "example.org", internal)
+
+	endpoints, err := source.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{DNSName: "db.corp.local", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1"}, Labels: endpoint.Labels{"view": "internal"}},
+		{DNSName: "web.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+		{DNSName: "app.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"web.example.org"}},
+	})
+
+	mockSource.AssertExpectations(t)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// srvValidationSource is a Source that checks the targets of the SRV endpoints of its wrapped source.
+type srvValidationSource struct {
+	source Source
+	strict bool
+}
+
+// NewSRVValidationSource creates a new srvValidationSource wrapping the provided Source
+// which drops invalid SRV targets.
+func NewSRVValidationSource(source Source) Source {
+	return &srvValidationSource{source: source}
+}
+
+// NewStrictSRVValidationSource creates a new srvValidationSource wrapping the provided Source
+// which fails on invalid SRV targets.
+func NewStrictSRVValidationSource(source Source) Source {
+	return &srvValidationSource{source: source, strict: true}
+}
+
+// validateSRVTarget checks that the target has the form "priority weight port target" with
+// priority and weight between 0 and 65535 and port between 1 and 65535.
+func validateSRVTarget(target string) error {
+	fields := strings.Fields(target)
+	if len(fields) != 4 {
+		return fmt.Errorf("SRV target %q must have the form \"priority weight port target\"", target)
+	}
+	if _, err := strconv.ParseUint(fields[0], 10, 16); err != nil {
+		return fmt.Errorf("invalid priority %q of SRV target %q", fields[0], target)
+	}
+	if _, err := strconv.ParseUint(fields[1], 10, 16); err != nil {
+		return fmt.Errorf("invalid weight %q of SRV target %q", fields[1], target)
+	}
+	if port, err := strconv.ParseUint(fields[2], 10, 16); err != nil || port == 0 {
+		return fmt.Errorf("invalid port %q of SRV target %q", fields[2], target)
+	}
+	return nil
+}
+
+// Endpoints collects endpoints from its wrapped source and checks the targets of its SRV endpoints.
+// Invalid targets are dropped, or fail the call in strict mode. SRV endpoints without valid targets
+// are dropped, all other endpoints are passed through untouched.
+func (ms *srvValidationSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeSRV {
+			result = append(result, ep)
+			continue
+		}
+
+		targets := endpoint.Targets{}
+		for _, t := range ep.Targets {
+			if err := validateSRVTarget(t); err != nil {
+				if ms.strict {
+					return nil, fmt.Errorf("endpoint %s: %w", ep.DNSName, err)
+				}
+				log.Warnf("Dropping target of endpoint %s: %v", ep.DNSName, err)
+				continue
+			}
+			targets = append(targets, t)
+		}
+		if len(targets) == 0 {
+			log.Debugf("Dropping endpoint %s without remaining targets", ep)
+			continue
+		}
+		if len(targets) != len(ep.Targets) {
+			ep = ep.DeepCopy()
+			ep.Targets = targets
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *srvValidationSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that srvValidationSource is a Source
+var _ Source = &srvValidationSource{}
+
+func TestValidateSRVTarget(t *testing.T) {
+	for _, tc := range []struct {
+		target      string
+		expectError string
+	}{
+		{"0 50 80 foo.example.org", ""},
+		{"65535 65535 65535 foo.example.org.", ""},
+		{"10 50 80", "must have the form"},
+		{"-1 50 80 foo.example.org", `invalid priority "-1"`},
+		{"10 65536 80 foo.example.org", `invalid weight "65536"`},
+		{"10 50 0 foo.example.org", `invalid port "0"`},
+		{"10 50 70000 foo.example.org", `invalid port "70000"`},
+		{"10 50 http foo.example.org", `invalid port "http"`},
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			err := validateSRVTarget(tc.target)
+			if tc.expectError == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
+
+func TestSRVValidationSource(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		constructor func(Source) Source
+		endpoints   []*endpoint.Endpoint
+		expected    []*endpoint.Endpoint
+		expectError string
+	}{
+		{
+			"valid SRV targets are kept",
+			NewStrictSRVValidationSource,
+			[]*endpoint.Endpoint{
+				{DNSName: "_http._tcp.example.org", RecordType: "SRV", Targets: endpoint.Targets{"0 50 80 foo.example.org", "10 50 8080 bar.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "_http._tcp.example.org", RecordType: "SRV", Targets: endpoint.Targets{"0 50 80 foo.example.org", "10 50 8080 bar.example.org"}},
+			},
+			"",
+		},
+		{
+			"invalid SRV targets are dropped",
+			NewSRVValidationSource,
+			[]*endpoint.Endpoint{
+				{DNSName: "_http._tcp.example.org", RecordType: "SRV", Targets: endpoint.Targets{"0 50 80 foo.example.org", "0 50 0 bar.example.org"}},
+				{DNSName: "_ldap._tcp.example.org", RecordType: "SRV", Targets: endpoint.Targets{"-1 50 389 ldap.example.org"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "_http._tcp.example.org", RecordType: "SRV", Targets: endpoint.Targets{"0 50 80 foo.example.org"}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			"",
+		},
+		{
+			"invalid SRV targets fail when strict",
+			NewStrictSRVValidationSource,
+			[]*endpoint.Endpoint{
+				{DNSName: "_http._tcp.example.org", RecordType: "SRV", Targets: endpoint.Targets{"0 50 80 foo.example.org", "0 50 65536 bar.example.org"}},
+			},
+			nil,
+			`endpoint _http._tcp.example.org: invalid port "65536"`,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := len(tc.endpoints[0].Targets)
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := tc.constructor(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if tc.expectError != "" {
+				require.ErrorContains(t, err, tc.expectError)
+			} else {
+				require.NoError(t, err)
+				validateEndpoints(t, endpoints, tc.expected)
+			}
+			require.Len(t, tc.endpoints[0].Targets, original, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
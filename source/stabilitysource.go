@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// stabilitySource is a Source that refuses to publish endpoints of its wrapped source that
+// differ too much from the ones published last, guarding against catastrophic misconfiguration.
+type stabilitySource struct {
+	source        Source
+	threshold     float64
+	confirmations int
+
+	mutex     sync.Mutex
+	endpoints []*endpoint.Endpoint
+	published bool
+	pending   []*endpoint.Endpoint
+	seen      int
+}
+
+// NewStabilitySource creates a new stabilitySource wrapping the provided Source.
+// The threshold is the percentage of endpoints allowed to change between two syncs.
+// A change exceeding the threshold is published anyway once the wrapped source returned it
+// for confirmations syncs in a row, so that intended large changes don't freeze the source.
+// A confirmations of zero or less never publishes such changes.
+func NewStabilitySource(source Source, threshold float64, confirmations int) Source {
+	return &stabilitySource{source: source, threshold: threshold, confirmations: confirmations}
+}
+
+// Endpoints collects endpoints from its wrapped source and compares them to the endpoints
+// published last. An endpoint counts as changed if it was added, removed or has different
+// targets. If the changed endpoints exceed the threshold percentage of the published ones,
+// the change is logged and the published endpoints are returned again, unless the same
+// change was seen for the configured number of syncs in a row.
+func (ms *stabilitySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if ms.published && len(ms.endpoints) > 0 {
+		changed := changedEndpoints(ms.endpoints, endpoints)
+		if percentage := float64(changed) * 100 / float64(len(ms.endpoints)); percentage > ms.threshold {
+			if ms.pending != nil && changedEndpoints(ms.pending, endpoints) == 0 {
+				ms.seen++
+			} else {
+				ms.pending = copyEndpoints(endpoints)
+				ms.seen = 1
+			}
+			if ms.confirmations <= 0 || ms.seen < ms.confirmations {
+				log.Errorf("Refusing to change %d of %d endpoints (%.1f%%) exceeding the threshold of %.1f%%, keeping the previous endpoints", changed, len(ms.endpoints), percentage, ms.threshold)
+				return copyEndpoints(ms.endpoints), nil
+			}
+			log.Warnf("Changing %d of %d endpoints (%.1f%%) exceeding the threshold of %.1f%% after seeing the change %d times in a row", changed, len(ms.endpoints), percentage, ms.threshold, ms.seen)
+		}
+	}
+
+	ms.endpoints = copyEndpoints(endpoints)
+	ms.published = true
+	ms.pending = nil
+	ms.seen = 0
+	return endpoints, nil
+}
+
+// changedEndpoints returns the number of endpoints added, removed or with different targets in current compared to previous.
+func changedEndpoints(previous, current []*endpoint.Endpoint) int {
+	targets := make(map[endpointKey]string, len(previous))
+	for _, ep := range previous {
+		targets[endpointKey{dnsName: ep.DNSName, recordType: ep.RecordType, setIdentifier: ep.SetIdentifier}] = targetsKey(ep.Targets)
+	}
+
+	changed := 0
+	for _, ep := range current {
+		key := endpointKey{dnsName: ep.DNSName, recordType: ep.RecordType, setIdentifier: ep.SetIdentifier}
+		previousTargets, ok := targets[key]
+		if !ok || previousTargets != targetsKey(ep.Targets) {
+			changed++
+		}
+		delete(targets, key)
+	}
+	return changed + len(targets)
+}
+
+func (ms *stabilitySource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
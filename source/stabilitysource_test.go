@@ -0,0 +1,189 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that stabilitySource is a Source
+var _ Source = &stabilitySource{}
+
+func TestStabilitySource(t *testing.T) {
+	initial := []*endpoint.Endpoint{
+		{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+		{DNSName: "c.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}},
+		{DNSName: "d.example.org", RecordType: "A", Targets: endpoint.Targets{"4.4.4.4", "5.5.5.5"}},
+	}
+
+	for _, tc := range []struct {
+		title     string
+		threshold float64
+		next      []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			title:     "change below the threshold is published",
+			threshold: 30,
+			next: []*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+				{DNSName: "c.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.4"}},
+				{DNSName: "d.example.org", RecordType: "A", Targets: endpoint.Targets{"5.5.5.5", "4.4.4.4"}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+				{DNSName: "c.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.4"}},
+				{DNSName: "d.example.org", RecordType: "A", Targets: endpoint.Targets{"5.5.5.5", "4.4.4.4"}},
+			},
+		},
+		{
+			title:     "change at the threshold is published",
+			threshold: 50,
+			next: []*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+				{DNSName: "c.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}},
+				{DNSName: "e.example.org", RecordType: "A", Targets: endpoint.Targets{"4.4.4.4", "5.5.5.5"}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+				{DNSName: "c.example.org", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}},
+				{DNSName: "e.example.org", RecordType: "A", Targets: endpoint.Targets{"4.4.4.4", "5.5.5.5"}},
+			},
+		},
+		{
+			title:     "change above the threshold keeps the previous endpoints",
+			threshold: 50,
+			next: []*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"6.6.6.6"}},
+			},
+			expected: initial,
+		},
+		{
+			title:     "losing all endpoints keeps the previous endpoints",
+			threshold: 90,
+			next:      []*endpoint.Endpoint{},
+			expected:  initial,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(initial, nil).Once()
+			mockSource.On("Endpoints").Return(tc.next, nil).Once()
+
+			source := NewStabilitySource(mockSource, tc.threshold, 0)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+			validateEndpoints(t, endpoints, initial)
+
+			endpoints, err = source.Endpoints(context.Background())
+			require.NoError(t, err)
+			validateEndpoints(t, endpoints, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStabilitySourceComparesToPublishedEndpoints(t *testing.T) {
+	initial := []*endpoint.Endpoint{
+		{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+	}
+	broken := []*endpoint.Endpoint{
+		{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"9.9.9.9"}},
+	}
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(initial, nil).Once()
+	mockSource.On("Endpoints").Return(broken, nil).Twice()
+	mockSource.On("Endpoints").Return(initial, nil).Once()
+
+	source := NewStabilitySource(mockSource, 50, 0)
+
+	for _, expected := range [][]*endpoint.Endpoint{initial, initial, initial, initial} {
+		endpoints, err := source.Endpoints(context.Background())
+		require.NoError(t, err)
+		validateEndpoints(t, endpoints, expected)
+	}
+
+	mockSource.AssertExpectations(t)
+}
+
+func TestStabilitySourceReturnsError(t *testing.T) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{}, errors.New("failed")).Once()
+
+	_, err := NewStabilitySource(mockSource, 50, 0).Endpoints(context.Background())
+	require.EqualError(t, err, "failed")
+
+	mockSource.AssertExpectations(t)
+}
+
+func TestStabilitySourceConfirmations(t *testing.T) {
+	initial := []*endpoint.Endpoint{
+		{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+	}
+	migrated := []*endpoint.Endpoint{
+		{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"9.9.9.9"}},
+		{DNSName: "b.example.org", RecordType: "A", Targets: endpoint.Targets{"8.8.8.8"}},
+	}
+	other := []*endpoint.Endpoint{
+		{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"7.7.7.7"}},
+	}
+
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(initial, nil).Once()
+	mockSource.On("Endpoints").Return(migrated, nil).Twice()
+	mockSource.On("Endpoints").Return(other, nil).Once()
+	mockSource.On("Endpoints").Return(migrated, nil).Times(3)
+
+	source := NewStabilitySource(mockSource, 50, 3)
+
+	for i, expected := range [][]*endpoint.Endpoint{
+		initial,
+		// the change is refused until it was seen three times in a row.
+		initial, initial,
+		// a different change resets the count.
+		initial,
+		initial, initial, migrated,
+	} {
+		endpoints, err := source.Endpoints(context.Background())
+		require.NoError(t, err)
+		validateEndpoints(t, endpoints, expected)
+		if i == 1 {
+			// modifying returned endpoints must not modify the published ones.
+			endpoints[0].Targets = endpoint.Targets{"6.6.6.6"}
+		}
+	}
+
+	mockSource.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// suffixFilterSource is a Source that removes endpoints whose DNS name doesn't end with
+// one of the configured suffixes from its wrapped source.
+type suffixFilterSource struct {
+	source   Source
+	suffixes []string
+}
+
+// NewSuffixFilterSource creates a new suffixFilterSource wrapping the provided Source.
+// An empty list of suffixes keeps all endpoints.
+func NewSuffixFilterSource(source Source, suffixes []string) Source {
+	normalized := make([]string, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		normalized = append(normalized, strings.TrimPrefix(normalizeDNSNameCase(suffix), "."))
+	}
+	return &suffixFilterSource{source: source, suffixes: normalized}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns only those whose DNS name
+// equals one of the suffixes or is a subdomain of it, ignoring case and trailing dots.
+func (ms *suffixFilterSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ms.suffixes) == 0 {
+		return endpoints, nil
+	}
+
+	result := []*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if !ms.match(normalizeDNSNameCase(ep.DNSName)) {
+			log.Debugf("Dropping endpoint %s not matching any suffix", ep)
+			continue
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// match returns true if the normalized DNS name ends with one of the suffixes.
+func (ms *suffixFilterSource) match(dnsName string) bool {
+	for _, suffix := range ms.suffixes {
+		if dnsName == suffix || strings.HasSuffix(dnsName, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ms *suffixFilterSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that suffixFilterSource is a Source
+var _ Source = &suffixFilterSource{}
+
+func TestSuffixFilterSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		suffixes  []string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"empty suffix list keeps all endpoints",
+			nil,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org"},
+				{DNSName: "foo.example.com"},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org"},
+				{DNSName: "foo.example.com"},
+			},
+		},
+		{
+			"exact zone matches",
+			[]string{"example.org"},
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org"},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org"},
+			},
+		},
+		{
+			"subdomains match case insensitively",
+			[]string{"Example.org"},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org"},
+				{DNSName: "BAR.baz.EXAMPLE.org"},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org"},
+				{DNSName: "BAR.baz.EXAMPLE.org"},
+			},
+		},
+		{
+			"trailing dots are ignored",
+			[]string{"example.org."},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org."},
+				{DNSName: "bar.example.org"},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org."},
+				{DNSName: "bar.example.org"},
+			},
+		},
+		{
+			"non matching names are dropped",
+			[]string{"example.org", "example.net"},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.com"},
+				{DNSName: "fooexample.org"},
+				{DNSName: "foo.example.net"},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.net"},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewSuffixFilterSource(mockSource, tc.suffixes)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// suffixSwapSource is a Source that replaces a DNS name suffix of the endpoints of its wrapped
+// source, e.g. to promote the records of a staging zone to the production zone.
+type suffixSwapSource struct {
+	source Source
+	from   string
+	to     string
+}
+
+// NewSuffixSwapSource creates a new suffixSwapSource wrapping the provided Source.
+func NewSuffixSwapSource(source Source, from, to string) Source {
+	return &suffixSwapSource{source: source, from: from, to: to}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of those whose DNS name
+// equals from or is a subdomain of it with the suffix replaced by to. Suffixes only match on label
+// boundaries, so with from "staging.example.org" the name "foostaging.example.org" is left alone.
+func (ms *suffixSwapSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if dnsName, ok := ms.swap(ep.DNSName); ok {
+			ep = ep.DeepCopy()
+			ep.DNSName = dnsName
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// swap returns the DNS name with the suffix replaced and whether it matched.
+func (ms *suffixSwapSource) swap(dnsName string) (string, bool) {
+	return swapDNSNameSuffix(dnsName, ms.from, ms.to)
+}
+
+// swapDNSNameSuffix returns the DNS name with the suffix from replaced by to and whether it matched.
+// Suffixes only match on label boundaries.
+func swapDNSNameSuffix(dnsName, from, to string) (string, bool) {
+	if dnsName == from {
+		return to, true
+	}
+	if strings.HasSuffix(dnsName, "."+from) {
+		return strings.TrimSuffix(dnsName, from) + to, true
+	}
+	return dnsName, false
+}
+
+func (ms *suffixSwapSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
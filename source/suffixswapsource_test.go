@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that suffixSwapSource is a Source
+var _ Source = &suffixSwapSource{}
+
+func TestSuffixSwapSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"subdomains of the suffix are swapped",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.staging.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.baz.staging.example.org", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.baz.example.org", Targets: endpoint.Targets{"5.6.7.8"}},
+			},
+		},
+		{
+			"the suffix itself is swapped",
+			[]*endpoint.Endpoint{
+				{DNSName: "staging.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			"non matching suffixes are kept",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foostaging.example.org", Targets: endpoint.Targets{"5.6.7.8"}},
+				{DNSName: "foo.staging.example.org.other", Targets: endpoint.Targets{"9.9.9.9"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foostaging.example.org", Targets: endpoint.Targets{"5.6.7.8"}},
+				{DNSName: "foo.staging.example.org.other", Targets: endpoint.Targets{"9.9.9.9"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].DNSName
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewSuffixSwapSource(mockSource, "staging.example.org", "example.org")
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].DNSName, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
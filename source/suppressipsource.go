@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// suppressIPSource is a Source that removes the records of one IP family from its wrapped source.
+// Address records of the kept type lose their targets of the suppressed family, address records
+// of the suppressed type are dropped and all other records are passed through untouched.
+type suppressIPSource struct {
+	source     Source
+	keepType   string
+	suppressed string
+	family     int
+}
+
+// NewSuppressIPv6Source creates a new suppressIPSource wrapping the provided Source
+// which only keeps IPv4 targets of A records and drops AAAA records.
+func NewSuppressIPv6Source(source Source) Source {
+	return &suppressIPSource{source: source, keepType: endpoint.RecordTypeA, suppressed: endpoint.RecordTypeAAAA, family: 4}
+}
+
+// NewSuppressIPv4Source creates a new suppressIPSource wrapping the provided Source
+// which only keeps IPv6 targets of AAAA records and drops A records.
+func NewSuppressIPv4Source(source Source) Source {
+	return &suppressIPSource{source: source, keepType: endpoint.RecordTypeAAAA, suppressed: endpoint.RecordTypeA, family: 6}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them with the suppressed
+// records removed. Filtered records are copied, records left without targets are dropped.
+func (ms *suppressIPSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		switch ep.RecordType {
+		case ms.suppressed:
+			log.Debugf("Dropping suppressed endpoint %s", ep)
+			continue
+		case ms.keepType:
+			targets := ep.Targets.FilterByIPFamily(ms.family)
+			if len(targets) == 0 {
+				log.Debugf("Dropping endpoint %s without remaining targets", ep)
+				continue
+			}
+			ep = ep.DeepCopy()
+			ep.Targets = targets
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *suppressIPSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
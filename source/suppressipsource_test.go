@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that suppressIPSource is a Source
+var _ Source = &suppressIPSource{}
+
+func TestSuppressIPSource(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		constructor func(Source) Source
+		endpoints   []*endpoint.Endpoint
+		expected    []*endpoint.Endpoint
+	}{
+		{
+			"IPv6 suppressor keeps IPv4 targets of mixed A records",
+			NewSuppressIPv6Source,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "2001:db8::1", "5.6.7.8"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+			},
+		},
+		{
+			"IPv4 suppressor keeps IPv6 targets of mixed AAAA records",
+			NewSuppressIPv4Source,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"1.2.3.4", "2001:db8::1", "5.6.7.8"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			"IPv4 suppressor treats IPv4-mapped IPv6 addresses as IPv4",
+			NewSuppressIPv4Source,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"::ffff:1.2.3.4", "2001:db8::1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			"IPv6 suppressor drops A records without IPv4 targets",
+			NewSuppressIPv6Source,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+			[]*endpoint.Endpoint{},
+		},
+		{
+			"IPv6 suppressor filters a mix of record types",
+			NewSuppressIPv6Source,
+			[]*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "2001:db8::1"}},
+				{DNSName: "aaaa.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+				{DNSName: "cname.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"a.example.org"}},
+				{DNSName: "txt.example.org", RecordType: "TXT", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "cname.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"a.example.org"}},
+				{DNSName: "txt.example.org", RecordType: "TXT", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			"IPv4 suppressor filters a mix of record types",
+			NewSuppressIPv4Source,
+			[]*endpoint.Endpoint{
+				{DNSName: "a.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "aaaa.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1", "1.2.3.4"}},
+				{DNSName: "cname.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"a.example.org"}},
+				{DNSName: "txt.example.org", RecordType: "TXT", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "aaaa.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+				{DNSName: "cname.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"a.example.org"}},
+				{DNSName: "txt.example.org", RecordType: "TXT", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].Targets.String()
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := tc.constructor(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].Targets.String(), "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// targetCIDRFilterSource is a Source that removes IP targets outside of the allowed or inside
+// of the denied networks from the endpoints of its wrapped source.
+type targetCIDRFilterSource struct {
+	source Source
+	allow  []*net.IPNet
+	deny   []*net.IPNet
+}
+
+// NewTargetCIDRFilterSource creates a new targetCIDRFilterSource wrapping the provided Source.
+// An empty allow list allows all addresses.
+func NewTargetCIDRFilterSource(source Source, allow []*net.IPNet, deny []*net.IPNet) Source {
+	return &targetCIDRFilterSource{source: source, allow: allow, deny: deny}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of its A and AAAA endpoints
+// keeping only the IP targets within an allowed network and outside all denied networks. Targets
+// which aren't IP addresses are kept, and other record types are passed through unchanged.
+// Endpoints whose targets were all filtered out are dropped.
+func (ms *targetCIDRFilterSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if ep == nil || (ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA) {
+			result = append(result, ep)
+			continue
+		}
+		targets := endpoint.Targets{}
+		for _, t := range ep.Targets {
+			if ip := net.ParseIP(t); ip == nil || ms.keep(ip) {
+				targets = append(targets, t)
+			}
+		}
+		if len(targets) == len(ep.Targets) {
+			result = append(result, ep)
+			continue
+		}
+		if len(targets) == 0 {
+			log.Debugf("Dropping endpoint %s without remaining targets", ep)
+			continue
+		}
+		ep = ep.DeepCopy()
+		ep.Targets = targets
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// keep returns true if the address is allowed and not denied.
+func (ms *targetCIDRFilterSource) keep(ip net.IP) bool {
+	return (len(ms.allow) == 0 || containsIP(ms.allow, ip)) && !containsIP(ms.deny, ip)
+}
+
+// containsIP returns true if one of the networks contains the address.
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ms *targetCIDRFilterSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
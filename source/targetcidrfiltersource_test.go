@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that targetCIDRFilterSource is a Source
+var _ Source = &targetCIDRFilterSource{}
+
+func mustParseCIDRs(t *testing.T, cidrs ...string) []*net.IPNet {
+	t.Helper()
+
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func TestTargetCIDRFilterSource(t *testing.T) {
+	rfc1918 := mustParseCIDRs(t, "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16")
+
+	for _, tc := range []struct {
+		title     string
+		allow     []*net.IPNet
+		deny      []*net.IPNet
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"private addresses are denied",
+			nil,
+			rfc1918,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.1.2.3"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"192.168.1.1"}},
+				{DNSName: "baz.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "baz.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			"only allowed public addresses are kept",
+			mustParseCIDRs(t, "203.0.113.0/24"),
+			nil,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"203.0.113.10"}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"198.51.100.10"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"203.0.113.10"}},
+			},
+		},
+		{
+			"mixed targets within one endpoint are filtered",
+			mustParseCIDRs(t, "0.0.0.0/0"),
+			rfc1918,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.1.2.3", "1.2.3.4", "172.16.0.1", "5.6.7.8"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+			},
+		},
+		{
+			"CNAME targets pass through",
+			mustParseCIDRs(t, "203.0.113.0/24"),
+			rfc1918,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"lb.example.org"}},
+			},
+		},
+		{
+			"other record types with address targets pass through",
+			nil,
+			rfc1918,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"10.1.2.3"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "TXT", Targets: endpoint.Targets{"10.1.2.3"}},
+			},
+		},
+		{
+			"endpoints without targets are kept",
+			nil,
+			rfc1918,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{}},
+				{DNSName: "bar.example.org", RecordType: "A", Targets: endpoint.Targets{"10.1.2.3"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].Targets.String()
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewTargetCIDRFilterSource(mockSource, tc.allow, tc.deny)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].Targets.String(), "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// ttlOverrideSource is a Source that clamps the TTLs of its wrapped source into a range.
+type ttlOverrideSource struct {
+	source     Source
+	min        endpoint.TTL
+	max        endpoint.TTL
+	defaultTTL endpoint.TTL
+}
+
+// NewTTLOverrideSource creates a new ttlOverrideSource wrapping the provided Source.
+// A max of zero or less means no upper bound. The min must not exceed a set max.
+func NewTTLOverrideSource(source Source, min, max endpoint.TTL) (Source, error) {
+	return NewTTLOverrideSourceWithDefault(source, min, max, 0)
+}
+
+// NewTTLOverrideSourceWithDefault creates a new ttlOverrideSource wrapping the provided Source
+// which additionally sets unconfigured TTLs to the given default.
+func NewTTLOverrideSourceWithDefault(source Source, min, max, defaultTTL endpoint.TTL) (Source, error) {
+	if max > 0 && min > max {
+		return nil, fmt.Errorf("invalid TTL range [%d, %d]", min, max)
+	}
+	return &ttlOverrideSource{source: source, min: min, max: max, defaultTTL: defaultTTL}, nil
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them with each configured
+// TTL clamped into [min, max], or raised to min without max. Unconfigured TTLs are set to the default, if any.
+// Changed endpoints are copied.
+func (ms *ttlOverrideSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ttl := ms.override(ep.RecordTTL); ttl != ep.RecordTTL {
+			log.Debugf("Overriding TTL of %s from %d to %d", ep.DNSName, ep.RecordTTL, ttl)
+			ep = ep.DeepCopy()
+			ep.RecordTTL = ttl
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+// override returns the TTL to use instead of ttl.
+func (ms *ttlOverrideSource) override(ttl endpoint.TTL) endpoint.TTL {
+	switch {
+	case !ttl.IsConfigured():
+		return ms.defaultTTL
+	case ttl < ms.min:
+		return ms.min
+	case ms.max > 0 && ttl > ms.max:
+		return ms.max
+	}
+	return ttl
+}
+
+func (ms *ttlOverrideSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
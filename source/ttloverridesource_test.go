@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that ttlOverrideSource is a Source
+var _ Source = &ttlOverrideSource{}
+
+func TestTTLOverrideSource(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		source   func(Source) (Source, error)
+		ttl      endpoint.TTL
+		expected endpoint.TTL
+	}{
+		{
+			title:    "TTL below min is raised",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSource(s, 60, 3600) },
+			ttl:      5,
+			expected: 60,
+		},
+		{
+			title:    "TTL above max is lowered",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSource(s, 60, 3600) },
+			ttl:      86400,
+			expected: 3600,
+		},
+		{
+			title:    "TTL in range is kept",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSource(s, 60, 3600) },
+			ttl:      300,
+			expected: 300,
+		},
+		{
+			title:    "TTL below min is raised without max",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSource(s, 60, 0) },
+			ttl:      5,
+			expected: 60,
+		},
+		{
+			title:    "TTL above min is kept without max",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSource(s, 60, 0) },
+			ttl:      86400,
+			expected: 86400,
+		},
+		{
+			title:    "TTL above max is lowered without min",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSource(s, 0, 3600) },
+			ttl:      86400,
+			expected: 3600,
+		},
+		{
+			title:    "TTL below max is kept without min",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSource(s, 0, 3600) },
+			ttl:      5,
+			expected: 5,
+		},
+		{
+			title:    "unconfigured TTL is kept without default",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSource(s, 60, 3600) },
+			ttl:      0,
+			expected: 0,
+		},
+		{
+			title:    "unconfigured TTL receives the default",
+			source:   func(s Source) (Source, error) { return NewTTLOverrideSourceWithDefault(s, 60, 3600, 600) },
+			ttl:      0,
+			expected: 600,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := &endpoint.Endpoint{
+				DNSName:          "foo.example.org",
+				RecordType:       "A",
+				Targets:          endpoint.Targets{"1.2.3.4"},
+				RecordTTL:        tc.ttl,
+				SetIdentifier:    "a",
+				Labels:           endpoint.Labels{"foo": "bar"},
+				ProviderSpecific: endpoint.ProviderSpecific{{Name: "alias", Value: "false"}},
+			}
+			expected := original.DeepCopy()
+			expected.RecordTTL = tc.expected
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return([]*endpoint.Endpoint{original}, nil)
+
+			source, err := tc.source(mockSource)
+			require.NoError(t, err)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			require.Len(t, endpoints, 1)
+			require.Equal(t, expected, endpoints[0])
+			require.Equal(t, tc.ttl, original.RecordTTL, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTTLOverrideSourceInvalidRange(t *testing.T) {
+	_, err := NewTTLOverrideSource(new(testutils.MockSource), 3600, 60)
+	require.EqualError(t, err, "invalid TTL range [3600, 60]")
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// txtLengthGuardSource is a Source that keeps the TXT record values of its wrapped source
+// within the maximum length supported by a provider.
+type txtLengthGuardSource struct {
+	source Source
+	maxLen int
+	chunk  bool
+}
+
+// NewTXTLengthGuardSource creates a new txtLengthGuardSource wrapping the provided Source.
+// TXT endpoints with a value longer than maxLen are dropped, or if chunk is set,
+// have that value split into character-strings of at most maxLen bytes within the same record.
+func NewTXTLengthGuardSource(source Source, maxLen int, chunk bool) Source {
+	return &txtLengthGuardSource{source: source, maxLen: maxLen, chunk: chunk}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them with TXT
+// values exceeding the maximum length dropped or chunked.
+func (ms *txtLengthGuardSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeTXT || ms.maxLen <= 0 || !ms.exceeds(ep.Targets) {
+			result = append(result, ep)
+			continue
+		}
+
+		if !ms.chunk {
+			log.Warnf("Dropping TXT endpoint %s with a value longer than %d characters", ep.DNSName, ms.maxLen)
+			continue
+		}
+
+		chunked := ep.DeepCopy()
+		chunked.Targets = endpoint.Targets{}
+		for _, t := range ep.Targets {
+			// all values are quoted, so that the endpoint doesn't mix quoted and plain values
+			if !ms.isChunked(t) {
+				t = chunkTXT(t, ms.maxLen)
+			}
+			chunked.Targets = append(chunked.Targets, t)
+		}
+		log.Debugf("Chunked TXT endpoint %s into values of at most %d characters", ep.DNSName, ms.maxLen)
+		result = append(result, chunked)
+	}
+
+	return result, nil
+}
+
+// chunkTXT splits the value into quoted character-strings of at most maxLen bytes, cut on
+// rune boundaries, and joins them into a single value, e.g. "abc" "def". Each target is a
+// separate TXT record, so the chunks of a value must stay within the same target.
+func chunkTXT(value string, maxLen int) string {
+	var chunks []string
+	for value != "" {
+		end := 0
+		for end < len(value) {
+			_, size := utf8.DecodeRuneInString(value[end:])
+			if end > 0 && end+size > maxLen {
+				break
+			}
+			end += size
+		}
+		chunks = append(chunks, quoteTXT(value[:end]))
+		value = value[end:]
+	}
+	return strings.Join(chunks, " ")
+}
+
+// quoteTXT quotes a character-string, escaping quotes and backslashes.
+func quoteTXT(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// splitTXTChunks returns the unquoted character-strings of a value made of space separated
+// quoted character-strings as returned by chunkTXT, or false if the value is not of that form.
+func splitTXTChunks(value string) ([]string, bool) {
+	var chunks []string
+	for {
+		if value == "" || value[0] != '"' {
+			return nil, false
+		}
+		var b strings.Builder
+		i := 1
+		for ; i < len(value) && value[i] != '"'; i++ {
+			if value[i] == '\\' {
+				i++
+				if i == len(value) {
+					return nil, false
+				}
+			}
+			b.WriteByte(value[i])
+		}
+		if i == len(value) {
+			return nil, false
+		}
+		chunks = append(chunks, b.String())
+		value = value[i+1:]
+		if value == "" {
+			return chunks, true
+		}
+		if value[0] != ' ' {
+			return nil, false
+		}
+		value = value[1:]
+	}
+}
+
+// isChunked returns true if the value consists of quoted character-strings within the
+// maximum length, e.g. a value chunked on an earlier pass.
+func (ms *txtLengthGuardSource) isChunked(value string) bool {
+	chunks, ok := splitTXTChunks(value)
+	if !ok {
+		return false
+	}
+	for _, chunk := range chunks {
+		if len(chunk) > ms.maxLen {
+			return false
+		}
+	}
+	return true
+}
+
+// exceeds returns true if any of the targets is longer than the maximum length and not
+// chunked already.
+func (ms *txtLengthGuardSource) exceeds(targets endpoint.Targets) bool {
+	for _, t := range targets {
+		if len(t) > ms.maxLen && !ms.isChunked(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ms *txtLengthGuardSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
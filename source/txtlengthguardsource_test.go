@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that txtLengthGuardSource is a Source
+var _ Source = &txtLengthGuardSource{}
+
+func TestTXTLengthGuardSource(t *testing.T) {
+	newEndpoints := func() []*endpoint.Endpoint {
+		return []*endpoint.Endpoint{
+			{DNSName: "short.example.org", RecordType: "TXT", Targets: endpoint.Targets{"abcd"}},
+			{DNSName: "long.example.org", RecordType: "TXT", Targets: endpoint.Targets{"abcdefghij", "xy"}},
+			{DNSName: "long.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"abcdefghij.example.org"}},
+		}
+	}
+
+	for _, tc := range []struct {
+		title    string
+		maxLen   int
+		chunk    bool
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title:  "over limit values are dropped",
+			maxLen: 4,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "short.example.org", RecordType: "TXT", Targets: endpoint.Targets{"abcd"}},
+				{DNSName: "long.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"abcdefghij.example.org"}},
+			},
+		},
+		{
+			title:  "over limit values are chunked",
+			maxLen: 4,
+			chunk:  true,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "short.example.org", RecordType: "TXT", Targets: endpoint.Targets{"abcd"}},
+				{DNSName: "long.example.org", RecordType: "TXT", Targets: endpoint.Targets{`"abcd" "efgh" "ij"`, `"xy"`}},
+				{DNSName: "long.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"abcdefghij.example.org"}},
+			},
+		},
+		{
+			title:    "values within limit are kept",
+			maxLen:   255,
+			expected: newEndpoints(),
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			endpoints := newEndpoints()
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(endpoints, nil)
+
+			source := NewTXTLengthGuardSource(mockSource, tc.maxLen, tc.chunk)
+
+			result, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, result, tc.expected)
+			require.Equal(t, endpoint.Targets{"abcdefghij", "xy"}, endpoints[1].Targets, "wrapped endpoints must not be modified")
+
+			// applying the source to its own output changes nothing
+			again := new(testutils.MockSource)
+			again.On("Endpoints").Return(result, nil)
+			twice, err := NewTXTLengthGuardSource(again, tc.maxLen, tc.chunk).Endpoints(context.Background())
+			require.NoError(t, err)
+			validateEndpoints(t, twice, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChunkTXT(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		maxLen   int
+		expected string
+	}{
+		{"abcdefghij", 4, `"abcd" "efgh" "ij"`},
+		{"abcdefgh", 4, `"abcd" "efgh"`},
+		{"héllo", 2, `"h" "é" "ll" "o"`},
+		{"ab€", 2, `"ab" "€"`},
+		{`a"b\c`, 3, `"a\"b" "\\c"`},
+	} {
+		require.Equal(t, tc.expected, chunkTXT(tc.value, tc.maxLen), "value %q", tc.value)
+	}
+}
+
+func TestSplitTXTChunks(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected []string
+		ok       bool
+	}{
+		{`"abcd" "efgh" "ij"`, []string{"abcd", "efgh", "ij"}, true},
+		{`"a\"b" "\\c"`, []string{`a"b`, `\c`}, true},
+		{`"abcd"`, []string{"abcd"}, true},
+		{`abcd`, nil, false},
+		{`"abcd" efgh`, nil, false},
+		{`"abcd"  "efgh"`, nil, false},
+		{`"abcd`, nil, false},
+		{`"ab\`, nil, false},
+	} {
+		chunks, ok := splitTXTChunks(tc.value)
+		require.Equal(t, tc.ok, ok, "value %q", tc.value)
+		require.Equal(t, tc.expected, chunks, "value %q", tc.value)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// ValidateModeDrop makes validateSource log and drop invalid endpoints.
+	ValidateModeDrop = "drop"
+	// ValidateModeFail makes validateSource fail on the first invalid endpoint.
+	ValidateModeFail = "fail"
+)
+
+// validateSource is a Source that rejects malformed endpoints of its wrapped source.
+type validateSource struct {
+	source Source
+	mode   string
+}
+
+// NewValidateSource creates a new validateSource wrapping the provided Source. The mode is
+// either ValidateModeDrop or ValidateModeFail, any other mode fails on invalid endpoints.
+func NewValidateSource(source Source, mode string) Source {
+	return &validateSource{source: source, mode: mode}
+}
+
+// checkEndpoint checks that the endpoint has a DNS name, that the targets of A records are
+// IPv4 addresses, those of AAAA records IPv6 addresses and those of CNAME records hostnames.
+func checkEndpoint(ep *endpoint.Endpoint) error {
+	if ep.DNSName == "" {
+		return fmt.Errorf("endpoint without DNS name")
+	}
+	for _, t := range ep.Targets {
+		switch ep.RecordType {
+		case endpoint.RecordTypeA:
+			if addr, err := netip.ParseAddr(t); err != nil || !addr.Is4() {
+				return fmt.Errorf("target %q of A record is not an IPv4 address", t)
+			}
+		case endpoint.RecordTypeAAAA:
+			if addr, err := netip.ParseAddr(t); err != nil || !addr.Is6() || addr.Is4In6() {
+				return fmt.Errorf("target %q of AAAA record is not an IPv6 address", t)
+			}
+		case endpoint.RecordTypeCNAME:
+			hostname := strings.TrimSuffix(t, ".")
+			if errs := validation.IsDNS1123Subdomain(strings.ToLower(hostname)); len(errs) > 0 {
+				return fmt.Errorf("target %q of CNAME record is not a hostname: %s", t, strings.Join(errs, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Endpoints collects endpoints from its wrapped source and checks them. Invalid endpoints
+// are dropped in drop mode, otherwise the first invalid endpoint fails the call.
+func (ms *validateSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if err := checkEndpoint(ep); err != nil {
+			if ms.mode != ValidateModeDrop {
+				return nil, fmt.Errorf("invalid endpoint %s: %w", ep, err)
+			}
+			log.Warnf("Dropping invalid endpoint %s: %v", ep, err)
+			continue
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *validateSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
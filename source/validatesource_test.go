@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that validateSource is a Source
+var _ Source = &validateSource{}
+
+func TestValidateSource(t *testing.T) {
+	valid := &endpoint.Endpoint{DNSName: "valid.example.org", RecordType: "TXT", Targets: endpoint.Targets{"text"}}
+
+	for _, tc := range []struct {
+		title       string
+		endpoint    *endpoint.Endpoint
+		expectError string
+	}{
+		{
+			title:    "A record with IPv4 target",
+			endpoint: &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+		},
+		{
+			title:       "A record with hostname target",
+			endpoint:    &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "foo-{{.Name}}.example.org"}},
+			expectError: `target "foo-{{.Name}}.example.org" of A record is not an IPv4 address`,
+		},
+		{
+			title:       "A record with IPv6 target",
+			endpoint:    &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"2001:db8::1"}},
+			expectError: `target "2001:db8::1" of A record is not an IPv4 address`,
+		},
+		{
+			title:    "AAAA record with IPv6 target",
+			endpoint: &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}},
+		},
+		{
+			title:       "AAAA record with IPv4 target",
+			endpoint:    &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"1.2.3.4"}},
+			expectError: `target "1.2.3.4" of AAAA record is not an IPv6 address`,
+		},
+		{
+			title:       "AAAA record with IPv4-mapped target",
+			endpoint:    &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"::ffff:1.2.3.4"}},
+			expectError: `target "::ffff:1.2.3.4" of AAAA record is not an IPv6 address`,
+		},
+		{
+			title:    "CNAME record with hostname target",
+			endpoint: &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"bar.example.org."}},
+		},
+		{
+			title:       "CNAME record with invalid hostname target",
+			endpoint:    &endpoint.Endpoint{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"bar_{{.Name}}.example.org"}},
+			expectError: `target "bar_{{.Name}}.example.org" of CNAME record is not a hostname`,
+		},
+		{
+			title:       "record without DNS name",
+			endpoint:    &endpoint.Endpoint{RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			expectError: "endpoint without DNS name",
+		},
+	} {
+		for _, mode := range []string{ValidateModeDrop, ValidateModeFail} {
+			t.Run(tc.title+" in "+mode+" mode", func(t *testing.T) {
+				mockSource := new(testutils.MockSource)
+				mockSource.On("Endpoints").Return([]*endpoint.Endpoint{valid, tc.endpoint}, nil)
+
+				source := NewValidateSource(mockSource, mode)
+
+				endpoints, err := source.Endpoints(context.Background())
+				switch {
+				case tc.expectError == "":
+					require.NoError(t, err)
+					validateEndpoints(t, endpoints, []*endpoint.Endpoint{valid, tc.endpoint})
+				case mode == ValidateModeDrop:
+					require.NoError(t, err)
+					validateEndpoints(t, endpoints, []*endpoint.Endpoint{valid})
+				default:
+					require.ErrorContains(t, err, tc.expectError)
+				}
+
+				mockSource.AssertExpectations(t)
+			})
+		}
+	}
+}
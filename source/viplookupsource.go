@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// vipLookupSource is a Source that replaces the node IP targets of its wrapped source
+// with the virtual IPs they are mapped to.
+type vipLookupSource struct {
+	source Source
+	lookup func(target string) (string, bool)
+}
+
+// NewVIPLookupSource creates a new vipLookupSource wrapping the provided Source.
+// The lookup function returns the VIP of a target and whether one is known.
+func NewVIPLookupSource(source Source, lookup func(target string) (string, bool)) Source {
+	return &vipLookupSource{source: source, lookup: lookup}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of them with
+// the targets of A records replaced by their VIPs when available. Targets without
+// a VIP are kept, targets mapping to the same VIP are collapsed.
+func (ms *vipLookupSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA {
+			result = append(result, ep)
+			continue
+		}
+
+		rewritten := ep.DeepCopy()
+		rewritten.Targets = endpoint.Targets{}
+		for _, t := range ep.Targets {
+			if vip, ok := ms.lookup(t); ok {
+				log.Debugf("Replacing target %s of %s with VIP %s", t, ep.DNSName, vip)
+				t = vip
+			}
+			rewritten.Targets = append(rewritten.Targets, t)
+		}
+		rewritten.Targets = rewritten.Targets.Dedup()
+		result = append(result, rewritten)
+	}
+
+	return result, nil
+}
+
+func (ms *vipLookupSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
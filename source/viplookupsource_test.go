@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that vipLookupSource is a Source
+var _ Source = &vipLookupSource{}
+
+func TestVIPLookupSource(t *testing.T) {
+	vips := map[string]string{
+		"10.0.0.1": "1.1.1.1",
+		"10.0.0.2": "1.1.1.1",
+		"10.0.0.3": "3.3.3.3",
+	}
+	lookup := func(target string) (string, bool) {
+		vip, ok := vips[target]
+		return vip, ok
+	}
+
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"hits are replaced",
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "A", Targets: endpoint.Targets{"10.0.0.3"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}},
+			},
+		},
+		{
+			"misses are kept",
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes", RecordType: "A", Targets: endpoint.Targets{"10.0.0.3", "10.0.0.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3", "10.0.0.4"}},
+			},
+		},
+		{
+			"targets sharing a VIP are collapsed",
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.2"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "nodes", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+			},
+		},
+		{
+			"non A records are untouched",
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "TXT", Targets: endpoint.Targets{"10.0.0.1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "node1", RecordType: "TXT", Targets: endpoint.Targets{"10.0.0.1"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].Targets.String()
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewVIPLookupSource(mockSource, lookup)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].Targets.String(), "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net/netip"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// VisibilityLabelKey is the name of the label holding whether the targets of an endpoint are public or private.
+	VisibilityLabelKey = "visibility"
+	// VisibilityPublic marks endpoints with publicly routable targets.
+	VisibilityPublic = "public"
+	// VisibilityPrivate marks endpoints with private targets.
+	VisibilityPrivate = "private"
+)
+
+// visibilitySplitSource is a Source that splits the address records of its wrapped source
+// by the visibility of their targets, so that public and private zones get only their share.
+type visibilitySplitSource struct {
+	source Source
+}
+
+// NewVisibilitySplitSource creates a new visibilitySplitSource wrapping the provided Source.
+func NewVisibilitySplitSource(source Source) Source {
+	return &visibilitySplitSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns copies of its A and AAAA
+// endpoints labeled with the visibility of their targets. Endpoints with both private and public
+// targets are split into one endpoint per visibility. Targets in private ranges (RFC 1918 and
+// RFC 4193) are private, all others are public. Other records are passed through.
+func (ms *visibilitySplitSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA {
+			result = append(result, ep)
+			continue
+		}
+
+		public, private := endpoint.Targets{}, endpoint.Targets{}
+		for _, t := range ep.Targets {
+			if addr, err := netip.ParseAddr(t); err == nil && addr.IsPrivate() {
+				private = append(private, t)
+			} else {
+				public = append(public, t)
+			}
+		}
+
+		for _, split := range []struct {
+			visibility string
+			targets    endpoint.Targets
+		}{
+			{VisibilityPublic, public},
+			{VisibilityPrivate, private},
+		} {
+			if len(split.targets) == 0 {
+				continue
+			}
+			tagged := ep.DeepCopy()
+			if tagged.Labels == nil {
+				tagged.Labels = endpoint.NewLabels()
+			}
+			tagged.Labels[VisibilityLabelKey] = split.visibility
+			tagged.Targets = split.targets
+			result = append(result, tagged)
+		}
+	}
+
+	return result, nil
+}
+
+func (ms *visibilitySplitSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that visibilitySplitSource is a Source
+var _ Source = &visibilitySplitSource{}
+
+func TestVisibilitySplitSource(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"mixed targets are split into a public and a private endpoint",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "10.0.0.1", "5.6.7.8", "192.168.1.1"}, Labels: endpoint.Labels{"foo": "bar"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}, Labels: endpoint.Labels{"foo": "bar", VisibilityLabelKey: VisibilityPublic}},
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1", "192.168.1.1"}, Labels: endpoint.Labels{"foo": "bar", VisibilityLabelKey: VisibilityPrivate}},
+			},
+		},
+		{
+			"mixed IPv6 targets are split into a public and a private endpoint",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"fd00::1", "2001:db8::1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"2001:db8::1"}, Labels: endpoint.Labels{VisibilityLabelKey: VisibilityPublic}},
+				{DNSName: "foo.example.org", RecordType: "AAAA", Targets: endpoint.Targets{"fd00::1"}, Labels: endpoint.Labels{VisibilityLabelKey: VisibilityPrivate}},
+			},
+		},
+		{
+			"private targets produce a single private endpoint",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1", "172.16.0.1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1", "172.16.0.1"}, Labels: endpoint.Labels{VisibilityLabelKey: VisibilityPrivate}},
+			},
+		},
+		{
+			"public targets produce a single public endpoint",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{VisibilityLabelKey: VisibilityPublic}},
+			},
+		},
+		{
+			"other records are passed through",
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"bar.example.org"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "CNAME", Targets: endpoint.Targets{"bar.example.org"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewVisibilitySplitSource(mockSource)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.NotContains(t, tc.endpoints[0].Labels, VisibilityLabelKey, "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
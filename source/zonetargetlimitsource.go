@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// zoneTargetLimitSource is a Source that caps the total number of targets of the endpoints
+// of its wrapped source within a zone.
+type zoneTargetLimitSource struct {
+	source     Source
+	zone       endpoint.DomainFilter
+	maxTargets int
+}
+
+// NewZoneTargetLimitSource creates a new zoneTargetLimitSource wrapping the provided Source.
+// A maxTargets of zero or less means no limit.
+func NewZoneTargetLimitSource(source Source, zone string, maxTargets int) Source {
+	if maxTargets <= 0 {
+		return source
+	}
+	return &zoneTargetLimitSource{source: source, zone: endpoint.NewDomainFilter([]string{zone}), maxTargets: maxTargets}
+}
+
+// Endpoints collects endpoints from its wrapped source and truncates the targets of the
+// endpoints within the zone until their sum doesn't exceed the limit. Targets are removed
+// one at a time from the endpoint with the most targets, the first one winning ties, and
+// endpoints within the zone left without targets are dropped. Endpoints outside the zone
+// are passed through.
+func (ms *zoneTargetLimitSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int, len(endpoints))
+	total := 0
+	for i, ep := range endpoints {
+		if ms.zone.Match(ep.DNSName) {
+			counts[i] = len(ep.Targets)
+			total += counts[i]
+		}
+	}
+	if total > ms.maxTargets {
+		log.Warnf("Truncating %d targets exceeding the limit of %d targets in the zone", total-ms.maxTargets, ms.maxTargets)
+	}
+	for ; total > ms.maxTargets; total-- {
+		largest := 0
+		for i := range counts {
+			if counts[i] > counts[largest] {
+				largest = i
+			}
+		}
+		counts[largest]--
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for i, ep := range endpoints {
+		switch {
+		case !ms.zone.Match(ep.DNSName):
+		case counts[i] == 0:
+			log.Debugf("Dropping endpoint %s without remaining targets", ep)
+			continue
+		case counts[i] == len(ep.Targets):
+		default:
+			ep = ep.DeepCopy()
+			ep.Targets = ep.Targets[:counts[i]]
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ms *zoneTargetLimitSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that zoneTargetLimitSource is a Source
+var _ Source = &zoneTargetLimitSource{}
+
+func TestZoneTargetLimitSource(t *testing.T) {
+	for _, tc := range []struct {
+		title      string
+		maxTargets int
+		endpoints  []*endpoint.Endpoint
+		expected   []*endpoint.Endpoint
+	}{
+		{
+			"endpoints within the limit are kept",
+			4,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}},
+				{DNSName: "bar.example.org", Targets: endpoint.Targets{"3.3.3.3", "4.4.4.4"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}},
+				{DNSName: "bar.example.org", Targets: endpoint.Targets{"3.3.3.3", "4.4.4.4"}},
+			},
+		},
+		{
+			"largest target sets are truncated first",
+			5,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2", "1.1.1.3", "1.1.1.4", "1.1.1.5"}},
+				{DNSName: "bar.example.org", Targets: endpoint.Targets{"2.2.2.1", "2.2.2.2"}},
+				{DNSName: "baz.example.org", Targets: endpoint.Targets{"3.3.3.1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2"}},
+				{DNSName: "bar.example.org", Targets: endpoint.Targets{"2.2.2.1", "2.2.2.2"}},
+				{DNSName: "baz.example.org", Targets: endpoint.Targets{"3.3.3.1"}},
+			},
+		},
+		{
+			"endpoints outside the zone are not counted nor truncated",
+			2,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2", "1.1.1.3"}},
+				{DNSName: "foo.example.com", Targets: endpoint.Targets{"2.2.2.1", "2.2.2.2", "2.2.2.3"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2"}},
+				{DNSName: "foo.example.com", Targets: endpoint.Targets{"2.2.2.1", "2.2.2.2", "2.2.2.3"}},
+			},
+		},
+		{
+			"endpoints left without targets are dropped",
+			1,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "bar.example.org", Targets: endpoint.Targets{"2.2.2.1"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "bar.example.org", Targets: endpoint.Targets{"2.2.2.1"}},
+			},
+		},
+		{
+			"endpoints without targets are dropped",
+			2,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1"}},
+				{DNSName: "bar.example.org", Targets: endpoint.Targets{}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1"}},
+			},
+		},
+		{
+			"a limit of zero means no limit",
+			0,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2"}},
+			},
+		},
+		{
+			"a negative limit means no limit",
+			-1,
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2"}},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.1.1.1", "1.1.1.2"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			original := tc.endpoints[0].Targets.String()
+
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewZoneTargetLimitSource(mockSource, "example.org", tc.maxTargets)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			require.Equal(t, original, tc.endpoints[0].Targets.String(), "wrapped endpoints must not be modified")
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}